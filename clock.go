@@ -0,0 +1,55 @@
+package sorm
+
+import (
+	"fmt"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"time"
+)
+
+// WithClock sets the function returning the current time used for the autocreatetime,
+// autoupdatetime and softdelete fields, time.Now by default
+func WithClock(clock func() time.Time) OpenOption {
+	return func(o *openOptions) {
+		o.clock = clock
+	}
+}
+
+func (q *DB) now() time.Time {
+	if q.clock == nil {
+		return time.Now()
+	}
+	return q.clock()
+}
+
+// setTimeField sets a time.Time or *time.Time field, if it can be set
+func setTimeField(v reflect.Value, now time.Time) {
+	if !v.CanSet() {
+		return
+	}
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.ValueOf(&now))
+	} else {
+		v.Set(reflect.ValueOf(now))
+	}
+}
+
+// setInsertTimes sets the autoupdatetime fields and the autocreatetime ones that are zero
+func setInsertTimes(model *ModelInfo, v reflect.Value, now time.Time) {
+	for _, f := range model.Fields {
+		val := v.FieldByIndex(f.StructFieldPath)
+		if f.IsAutoUpdate || (f.IsAutoCreate && val.IsZero()) {
+			setTimeField(val, now)
+		}
+	}
+}
+
+// setUpdateTimes sets the autoupdatetime fields and adds them to the values of an update
+func setUpdateTimes(model *ModelInfo, v reflect.Value, now time.Time, values builder.Eq) {
+	for _, f := range model.Fields {
+		if f.IsAutoUpdate {
+			setTimeField(v.FieldByIndex(f.StructFieldPath), now)
+			values[fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)] = now
+		}
+	}
+}
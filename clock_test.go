@@ -0,0 +1,78 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type timestampTestModel struct {
+	ID        int        `db:"id,primary,autoincrement"`
+	Name      string     `db:"name"`
+	CreatedAt time.Time  `db:"created_at,autocreatetime"`
+	UpdatedAt *time.Time `db:"updated_at,autoupdatetime"`
+}
+
+func (*timestampTestModel) TableName() string {
+	return "timestamp_test"
+}
+
+func TestAutoTimestamps(t *testing.T) {
+	AddModel(&timestampTestModel{})
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, fake := newFakeDB(DriverMysql, WithClock(func() time.Time {
+		return now
+	}))
+
+	m := &timestampTestModel{Name: "a"}
+	if err := Insert(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if !m.CreatedAt.Equal(now) || m.UpdatedAt == nil || !m.UpdatedAt.Equal(now) {
+		t.Fatalf("expected both times to be set, got %v and %v", m.CreatedAt, m.UpdatedAt)
+	}
+
+	now = now.Add(time.Hour)
+	created := m.CreatedAt
+	m.Name = ""
+	if err := Update(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if !m.CreatedAt.Equal(created) || !m.UpdatedAt.Equal(now) {
+		t.Fatalf("expected only the update time to change, got %v and %v", m.CreatedAt, m.UpdatedAt)
+	}
+
+	expected := "UPDATE `timestamp_test` SET `created_at`=?,`updated_at`=? WHERE `timestamp_test`.`id`=?"
+	if log := fake.log(); log[1] != expected {
+		t.Errorf("expected %q, got %q", expected, log[1])
+	}
+	if !reflect.DeepEqual(fake.args[1], []driver.Value{created, now, int64(1)}) {
+		t.Errorf("unexpected args %v", fake.args[1])
+	}
+}
+
+func TestAutoCreateNotUpdated(t *testing.T) {
+	AddModel(&timestampTestModel{})
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, fake := newFakeDB(DriverMysql, WithClock(func() time.Time {
+		return now
+	}))
+
+	// a model that was not scanned has a zero CreatedAt, which must not be written
+	m := &timestampTestModel{ID: 1, Name: "a"}
+	if err := UpdateAll(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateFields(db, m, "Name", "CreatedAt"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "UPDATE `timestamp_test` SET `name`=?,`updated_at`=? WHERE `timestamp_test`.`id`=?"
+	if log := fake.log(); !reflect.DeepEqual(log, []string{expected, expected}) {
+		t.Errorf("expected %q twice, got %q", expected, log)
+	}
+	if !m.CreatedAt.IsZero() {
+		t.Errorf("expected CreatedAt not to be set, got %v", m.CreatedAt)
+	}
+}
@@ -0,0 +1,141 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+// ReplicaPolicy chooses the replica used by a read
+type ReplicaPolicy int
+
+const (
+	// ReplicaRoundRobin uses the healthy replicas in turn
+	ReplicaRoundRobin ReplicaPolicy = iota
+	// ReplicaLeastLatency uses the healthy replica with the fastest last ping
+	ReplicaLeastLatency
+)
+
+// WithReplicaPolicy sets how OpenCluster picks the replica for a read, ReplicaRoundRobin by default
+func WithReplicaPolicy(policy ReplicaPolicy) OpenOption {
+	return func(o *openOptions) {
+		o.replicaPolicy = policy
+	}
+}
+
+type replica struct {
+	db        *sql.DB
+	health    *healthChecker
+	stmtCache *stmtCache
+}
+
+// healthy reports if the last ping of the replica succeeded, replicas that aren't pinged are always healthy
+func (r *replica) healthy() bool {
+	return r.health == nil || r.health.Status().LastError == nil
+}
+
+type replicaSet struct {
+	replicas []*replica
+	policy   ReplicaPolicy
+	next     uint64
+}
+
+// OpenCluster is like Open, but Find, Scan, Count, Select and Query are sent to one of the replicas,
+// skipping the ones whose last ping failed. Everything else, and every statement inside a
+// transaction, is sent to primary. If no replica is healthy the reads are sent to primary too.
+// Use DB.Primary to read from primary, e.g. to read your own writes.
+func OpenCluster(primary *sql.DB, replicas []*sql.DB, driver Driver, options ...OpenOption) *DB {
+	opts := newOpenOptions(options)
+	q := open(primary, driver, opts)
+
+	set := &replicaSet{policy: opts.replicaPolicy}
+	for _, db := range replicas {
+		r := &replica{db: db}
+		if !opts.noPing {
+			r.health = startHealthChecker(db, opts.pingInterval, opts.pingHandler)
+		}
+		if opts.stmtCacheSize > 0 {
+			r.stmtCache = newStmtCache(opts.stmtCacheSize)
+		}
+		set.replicas = append(set.replicas, r)
+	}
+	q.replicas = set
+	return q
+}
+
+// Primary returns a clone of the DB which sends every statement to the primary
+func (q *DB) Primary() *DB {
+	if q.replicas == nil || q.primaryOnly {
+		return q
+	}
+	clone := *q
+	clone.primaryOnly = true
+	return &clone
+}
+
+// replica returns the replica for a query executed with ctx, nil if it must be sent to the primary
+func (q *DB) replica(ctx context.Context) *replica {
+	if q.replicas == nil || q.primaryOnly {
+		return nil
+	}
+	info, _ := ctx.Value(operationKey{}).(operationInfo)
+	switch info.op {
+	case OpQuery, OpFind, OpScan, OpSelect, OpCount:
+		return q.replicas.pick()
+	}
+	return nil
+}
+
+// pick returns a healthy replica according to the policy, nil if there isn't any
+func (s *replicaSet) pick() *replica {
+	n := len(s.replicas)
+	if n == 0 {
+		return nil
+	}
+
+	if s.policy == ReplicaLeastLatency {
+		var best *replica
+		var bestStatus HealthStatus
+		for _, r := range s.replicas {
+			if r.health == nil {
+				return r
+			}
+			status := r.health.Status()
+			if status.LastError != nil {
+				continue
+			}
+			if best == nil || status.Latency < bestStatus.Latency {
+				best, bestStatus = r, status
+			}
+		}
+		return best
+	}
+
+	start := atomic.AddUint64(&s.next, 1) - 1
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *replicaSet) close() error {
+	if s == nil {
+		return nil
+	}
+	var firstErr error
+	for _, r := range s.replicas {
+		if r.health != nil {
+			r.health.Stop()
+		}
+		if r.stmtCache != nil {
+			r.stmtCache.close()
+		}
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,67 @@
+package sorm
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/n1xx1/builder"
+	"testing"
+	"time"
+)
+
+func TestClusterRouting(t *testing.T) {
+	AddModel(&queryTestModel{})
+	primary, primaryFake := newFakeSQLDB()
+	replica1, replicaFake1 := newFakeSQLDB()
+	replica2, replicaFake2 := newFakeSQLDB()
+	db := OpenCluster(primary, []*sql.DB{replica1, replica2}, DriverMysql, WithPingInterval(time.Hour))
+	defer db.Close()
+
+	find := func(q DBTX) {
+		var res []queryTestModel
+		if err := Find(q, builder.Select(), &res); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	find(db)
+	find(db)
+	if len(replicaFake1.log()) != 1 || len(replicaFake2.log()) != 1 || len(primaryFake.log()) != 0 {
+		t.Fatalf("expected the reads to be balanced on the replicas")
+	}
+
+	if err := Insert(db, &queryTestModel{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	find(db.Primary())
+	err := db.Begin(func(q *TX) error {
+		find(q)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replicaFake1.log()) != 1 || len(replicaFake2.log()) != 1 || len(primaryFake.log()) != 5 {
+		t.Fatalf("expected the writes, Primary and transactions to use the primary, got %q", primaryFake.log())
+	}
+
+	// replicas failing the ping are skipped
+	h := db.replicas.replicas[0].health
+	h.mu.Lock()
+	h.status.LastError = errors.New("ping failed")
+	h.mu.Unlock()
+	find(db)
+	find(db)
+	if len(replicaFake1.log()) != 1 || len(replicaFake2.log()) != 3 {
+		t.Fatalf("expected the unhealthy replica to be skipped")
+	}
+
+	// and the primary is used when none is healthy
+	h = db.replicas.replicas[1].health
+	h.mu.Lock()
+	h.status.LastError = errors.New("ping failed")
+	h.mu.Unlock()
+	find(db)
+	if len(primaryFake.log()) != 6 {
+		t.Fatalf("expected the read to fall back to the primary")
+	}
+}
@@ -9,24 +9,103 @@ import (
 )
 
 var ErrEmptyResult = fmt.Errorf("empty result")
+var ErrMissingWhere = fmt.Errorf("missing where condition")
+var ErrStaleObject = fmt.Errorf("stale object: the row was changed or deleted since it was read")
+var ErrPrimaryKeyChanged = fmt.Errorf("primary key changed since the model was read")
 
-func Open(db *sql.DB, driver Driver) *DB {
-	go periodicPing(db, pingOffset)
+const defaultPingInterval = time.Second * 30
+
+type openOptions struct {
+	pingInterval time.Duration
+	pingHandler  func(err error)
+	noPing       bool
+	logger       QueryLogger
+
+	slowThreshold time.Duration
+	slowHandler   func(e *QueryEvent)
+
+	hooks []Hook
+
+	stmtCacheSize int
+
+	replicaPolicy ReplicaPolicy
+
+	clock func() time.Time
+}
+
+// OpenOption configures the DB returned by Open
+type OpenOption func(o *openOptions)
+
+// WithPingInterval sets how often the database is pinged, 30 seconds by default.
+// A zero or negative interval keeps the default.
+func WithPingInterval(interval time.Duration) OpenOption {
+	return func(o *openOptions) {
+		if interval <= 0 {
+			interval = defaultPingInterval
+		}
+		o.pingInterval = interval
+	}
+}
+
+// WithPingHandler sets the function called after every ping with its result (nil on success).
+// By default failed pings are logged on stderr.
+func WithPingHandler(handler func(err error)) OpenOption {
+	return func(o *openOptions) {
+		o.pingHandler = handler
+	}
+}
+
+// WithoutPing disables the periodic ping
+func WithoutPing() OpenOption {
+	return func(o *openOptions) {
+		o.noPing = true
+	}
+}
+
+func defaultPingHandler(err error) {
+	if err != nil {
+		dbl.Println("ping error:", err)
+	}
+}
+
+// Open wraps db using the specified driver. Unless WithoutPing is used, the database is
+// periodically pinged until Close is called.
+func Open(db *sql.DB, driver Driver, options ...OpenOption) *DB {
+	return open(db, driver, newOpenOptions(options))
+}
+
+func newOpenOptions(options []OpenOption) *openOptions {
+	opts := &openOptions{
+		pingInterval: defaultPingInterval,
+		pingHandler:  defaultPingHandler,
+	}
+	for _, o := range options {
+		o(opts)
+	}
+	return opts
+}
+
+func open(db *sql.DB, driver Driver, opts *openOptions) *DB {
+	var health *healthChecker
+	if !opts.noPing {
+		health = startHealthChecker(db, opts.pingInterval, opts.pingHandler)
+	}
+	var cache *stmtCache
+	if opts.stmtCacheSize > 0 {
+		cache = newStmtCache(opts.stmtCacheSize)
+	}
 	return &DB{
 		db:     db,
 		stats:  &dbStats{},
 		driver: driver,
-	}
-}
+		health: health,
+		logger: opts.logger,
 
-var pingOffset time.Duration = 0
+		slowThreshold: opts.slowThreshold,
+		slowHandler:   opts.slowHandler,
 
-func periodicPing(db *sql.DB, offset time.Duration) {
-	for {
-		time.Sleep(time.Second*30 + offset)
-		err := db.Ping()
-		if err != nil {
-			fmt.Println(err)
-		}
+		hooks:     opts.hooks,
+		stmtCache: cache,
+		clock:     opts.clock,
 	}
 }
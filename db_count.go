@@ -1,11 +1,13 @@
 package sorm
 
 import (
+	"context"
 	"github.com/n1xx1/builder"
 )
 
-func doCountTx(calldepth int, q DBTX, b *builder.Builder) (int, error) {
-	qs, err := doQuery(calldepth+1, q, b, "COUNT(*)")
+func doCountTx(ctx context.Context, calldepth int, q DBTX, b *builder.Builder) (int, error) {
+	ctx = withOperation(ctx, OpCount, "")
+	qs, err := doQuery(ctx, calldepth+1, q, scopeSoftDelete(q, b), "COUNT(*)")
 	if err != nil {
 		return 0, err
 	}
@@ -21,5 +23,10 @@ func doCountTx(calldepth int, q DBTX, b *builder.Builder) (int, error) {
 
 /// Count queries the database with the specified query (b) with SELECT COUNT(*), and returns the number
 func Count(q DBTX, b *builder.Builder) (int, error) {
-	return doCountTx(1, q, b)
+	return doCountTx(context.Background(), 1, q, b)
+}
+
+/// CountContext is like Count but the query is bound to ctx
+func CountContext(ctx context.Context, q DBTX, b *builder.Builder) (int, error) {
+	return doCountTx(ctx, 1, q, b)
 }
@@ -1 +1,120 @@
 package sorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"time"
+)
+
+// doDeleteWhere deletes the rows matching cond, if the model has a softdelete field (and q is not
+// Unscoped) the rows that are not deleted yet are updated setting it to deletedAt instead
+func doDeleteWhere(ctx context.Context, calldepth int, q DBTX, model *ModelInfo, cond builder.Cond, deletedAt time.Time) (int64, error) {
+	if cond == nil || !cond.IsValid() {
+		return 0, ErrMissingWhere
+	}
+
+	ctx = withOperation(ctx, OpDelete, model.ModelName)
+	b := q.Driver().Dialect().Builder().From("[" + model.ModelName + "]")
+
+	if f := model.SoftDeleteField; f != nil && !isUnscoped(q) {
+		// the SET target can't be qualified with the table name on postgres and sqlite
+		fieldName := fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)
+		target := fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)
+		b = b.Where(cond).And(builder.IsNull{fieldName}).Update(builder.Eq{target: deletedAt})
+	} else {
+		b = b.Delete(cond)
+	}
+
+	sql1, args, err := b.ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("sql builder error: %w", err)
+	}
+
+	sql1, args = formatQuery(q, sql1, args)
+
+	res, err := timedExec(ctx, q, sql1, args, calldepth)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return affected, nil
+}
+
+func doDelete(ctx context.Context, calldepth int, q DBTX, i interface{}) error {
+	v := reflect.ValueOf(i)
+	if v.Type().Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	model := modelCache[v.Type()]
+	if model == nil {
+		panic("model not found")
+	}
+	if err := beforeDelete(q, i); err != nil {
+		return err
+	}
+
+	selects := builder.Eq{}
+	for _, f := range model.PrimaryFields {
+		val := v.FieldByIndex(f.StructFieldPath)
+		fieldName := fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)
+		selects[fieldName] = convertToDbType(val)
+	}
+
+	deletedAt := q.base().now()
+	affected, err := doDeleteWhere(ctx, calldepth+1, q, model, selects, deletedAt)
+	if err != nil {
+		return err
+	}
+	// if the row was already deleted, the field keeps the time it was deleted
+	if f := model.SoftDeleteField; f != nil && !isUnscoped(q) && affected > 0 {
+		if elem := v.FieldByIndex(f.StructFieldPath); elem.CanSet() {
+			elem.Set(reflect.ValueOf(&deletedAt))
+		}
+	}
+	return afterDelete(q, i)
+}
+
+// Delete deletes the row the model represent using it's primary fields for the WHERE.
+// If the model has no primary fields ErrMissingWhere is returned and nothing is deleted.
+// If the model has a softdelete field, the row is kept and the field is set to the current time,
+// unless q is Unscoped.
+func Delete(q DBTX, i interface{}) error {
+	return doDelete(context.Background(), 1, q, i)
+}
+
+// DeleteContext is like Delete but the query is bound to ctx
+func DeleteContext(ctx context.Context, q DBTX, i interface{}) error {
+	return doDelete(ctx, 1, q, i)
+}
+
+// DeleteWhere deletes every row of the table of the specified model (a struct or a pointer
+// to a struct) matching cond, and returns the number of deleted rows.
+// An empty cond returns ErrMissingWhere, to delete every row use a condition like builder.Expr("1=1").
+// Like Delete, the rows are soft deleted if the model has a softdelete field.
+func DeleteWhere(q DBTX, model interface{}, cond builder.Cond) (int64, error) {
+	return doDeleteWhere(context.Background(), 1, q, deleteModel(model), cond, q.base().now())
+}
+
+// DeleteWhereContext is like DeleteWhere but the query is bound to ctx
+func DeleteWhereContext(ctx context.Context, q DBTX, model interface{}, cond builder.Cond) (int64, error) {
+	return doDeleteWhere(ctx, 1, q, deleteModel(model), cond, q.base().now())
+}
+
+func deleteModel(model interface{}) *ModelInfo {
+	typ := reflect.TypeOf(model)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	m := modelCache[typ]
+	if m == nil {
+		panic("model not found")
+	}
+	return m
+}
@@ -0,0 +1,213 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingDBTX is a DBTX that never reaches a database, it only records the executed statements
+type recordingDBTX struct {
+	driver Driver
+	execs  []string
+	args   [][]interface{}
+}
+
+func (r *recordingDBTX) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.ExecContext(context.Background(), query, args...)
+}
+
+func (r *recordingDBTX) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.QueryContext(context.Background(), query, args...)
+}
+
+func (r *recordingDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.execs = append(r.execs, query)
+	r.args = append(r.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (r *recordingDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("query not supported")
+}
+
+func (r *recordingDBTX) Driver() Driver {
+	return r.driver
+}
+
+func (r *recordingDBTX) debugMode() bool {
+	return false
+}
+
+func (r *recordingDBTX) base() *DB {
+	return &DB{driver: r.driver}
+}
+
+type deleteTestModel struct {
+	ID   int    `db:"id,primary"`
+	Name string `db:"name"`
+}
+
+func (*deleteTestModel) TableName() string {
+	return "delete_test"
+}
+
+type deleteTestNoPrimary struct {
+	Name string `db:"name"`
+}
+
+func (*deleteTestNoPrimary) TableName() string {
+	return "delete_test_np"
+}
+
+func TestDelete(t *testing.T) {
+	AddModel(&deleteTestModel{})
+	AddModel(&deleteTestNoPrimary{})
+
+	q := &recordingDBTX{driver: DriverMysql}
+	err := Delete(q, &deleteTestModel{ID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "DELETE FROM `delete_test` WHERE `delete_test`.`id`=?"
+	if len(q.execs) != 1 || q.execs[0] != expected {
+		t.Fatalf("unexpected statements %q", q.execs)
+	}
+	if q.args[0][0] != 5 {
+		t.Fatalf("unexpected args %v", q.args[0])
+	}
+
+	err = Delete(q, &deleteTestNoPrimary{Name: "a"})
+	if !errors.Is(err, ErrMissingWhere) {
+		t.Fatalf("expected ErrMissingWhere, got %v", err)
+	}
+
+	_, err = DeleteWhere(q, deleteTestModel{}, builder.Eq{})
+	if !errors.Is(err, ErrMissingWhere) {
+		t.Fatalf("expected ErrMissingWhere, got %v", err)
+	}
+
+	n, err := DeleteWhere(q, deleteTestModel{}, builder.Gt{"[!deleteTestModel.ID]": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 affected row, got %d", n)
+	}
+	if len(q.execs) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(q.execs))
+	}
+}
+
+func TestDeleteContextCanceled(t *testing.T) {
+	AddModel(&deleteTestModel{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	q := &recordingDBTX{driver: DriverMysql}
+	err := DeleteContext(ctx, q, &deleteTestModel{ID: 5})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(q.execs) != 0 {
+		t.Fatalf("unexpected statements %q", q.execs)
+	}
+}
+
+type softDeleteTestModel struct {
+	ID        int        `db:"id,primary"`
+	DeletedAt *time.Time `db:"deleted_at,softdelete"`
+}
+
+func (*softDeleteTestModel) TableName() string {
+	return "soft_delete_test"
+}
+
+func TestSoftDelete(t *testing.T) {
+	AddModel(&softDeleteTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("COUNT(*)", []string{"count"}, []driver.Value{int64(0)})
+
+	m := &softDeleteTestModel{ID: 1}
+	if err := Delete(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.DeletedAt == nil {
+		t.Fatalf("expected DeletedAt to be set")
+	}
+	if err := Delete(Unscoped(db), m); err != nil {
+		t.Fatal(err)
+	}
+
+	var res []softDeleteTestModel
+	if err := Find(db, builder.Select(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Count(db, builder.Select().From("[softDeleteTestModel] s")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Find(Unscoped(db), builder.Select(), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"UPDATE `soft_delete_test` SET `deleted_at`=? WHERE `soft_delete_test`.`id`=? AND `soft_delete_test`.`deleted_at` IS NULL",
+		"DELETE FROM `soft_delete_test` WHERE `soft_delete_test`.`id`=?",
+		"SELECT `soft_delete_test`.`id` as q0,`soft_delete_test`.`deleted_at` as q1 FROM `soft_delete_test` WHERE `soft_delete_test`.`deleted_at` IS NULL",
+		"SELECT COUNT(*) as p0 FROM `soft_delete_test` s WHERE s.`deleted_at` IS NULL",
+		"SELECT `soft_delete_test`.`id` as q0,`soft_delete_test`.`deleted_at` as q1 FROM `soft_delete_test`",
+	}
+	if log := fake.log(); !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+}
+
+func TestSoftDeleteReuse(t *testing.T) {
+	AddModel(&softDeleteTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("COUNT(*)", []string{"count"}, []driver.Value{int64(0)})
+	fake.setAffected("UPDATE", 0)
+
+	// an already deleted row keeps its DeletedAt
+	m := &softDeleteTestModel{ID: 1}
+	if err := Delete(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.DeletedAt != nil {
+		t.Errorf("expected DeletedAt not to be set")
+	}
+
+	// the builder is not changed, so the condition is not repeated
+	b := builder.Select().From("[softDeleteTestModel]")
+	for i := 0; i < 2; i++ {
+		if _, err := Count(db, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := "SELECT COUNT(*) as p0 FROM `soft_delete_test` WHERE `soft_delete_test`.`deleted_at` IS NULL"
+	if log := fake.log(); len(log) != 3 || log[1] != expected || log[2] != expected {
+		t.Errorf("expected %q twice, got %q", expected, log)
+	}
+}
+
+func TestSoftDeleteNotUpdated(t *testing.T) {
+	AddModel(&softDeleteTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	// the only field left is the softdelete one, which would restore the row
+	if err := UpdateAll(db, &softDeleteTestModel{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if log := fake.log(); len(log) != 0 {
+		t.Errorf("unexpected statements %q", log)
+	}
+}
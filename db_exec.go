@@ -1,20 +1,21 @@
 package sorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/n1xx1/builder"
 )
 
-func doExecTx(calldepth int, q DBTX, b *builder.Builder) error {
+func doExecTx(ctx context.Context, calldepth int, q DBTX, b *builder.Builder) error {
+	ctx = withOperation(ctx, OpExec, "")
 	sql1, args, err := b.ToSQL()
 	if err != nil {
 		return fmt.Errorf("exec error: %w", err)
 	}
 
-	sql1 = FormatQuery(q.Driver(), sql1)
-	sql1, args = ConvertQuery(q.Driver(), sql1, args)
+	sql1, args = formatQuery(q, sql1, args)
 
-	_, err = timedExec(q, sql1, args, calldepth)
+	_, err = timedExec(ctx, q, sql1, args, calldepth)
 	if err != nil {
 		return fmt.Errorf("exec error: %w", err)
 	}
@@ -22,5 +23,9 @@ func doExecTx(calldepth int, q DBTX, b *builder.Builder) error {
 }
 
 func Exec(q DBTX, b *builder.Builder) error {
-	return doExecTx(1, q, b)
+	return doExecTx(context.Background(), 1, q, b)
+}
+
+func ExecContext(ctx context.Context, q DBTX, b *builder.Builder) error {
+	return doExecTx(ctx, 1, q, b)
 }
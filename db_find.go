@@ -1,12 +1,18 @@
 package sorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/n1xx1/builder"
 	"reflect"
 )
 
-func doFindTx(calldepth int, q DBTX, b *builder.Builder, dest interface{}) error {
+func doFindTx(ctx context.Context, calldepth int, q DBTX, b *builder.Builder, dest interface{}, options ...FindOption) error {
+	var opts findOptions
+	for _, o := range options {
+		o(&opts)
+	}
+
 	v := reflect.ValueOf(dest)
 	if v.Type().Kind() != reflect.Ptr || v.Type().Elem().Kind() != reflect.Slice {
 		return fmt.Errorf("dest parameter must be a pointer to slice")
@@ -26,7 +32,11 @@ func doFindTx(calldepth int, q DBTX, b *builder.Builder, dest interface{}) error
 	if isModel {
 		b = b.From("[" + model.ModelName + "]")
 		selectParams = []interface{}{model.ModelName}
+		ctx = withOperation(ctx, OpFind, model.ModelName)
+	} else {
+		ctx = withOperation(ctx, OpFind, "")
 	}
+	b = scopeSoftDelete(q, b)
 
 	var d1 reflect.Value
 	if !destIsPtr {
@@ -35,7 +45,7 @@ func doFindTx(calldepth int, q DBTX, b *builder.Builder, dest interface{}) error
 		d1 = reflect.New(elType)
 	}
 
-	qs, err := doQuery(calldepth+1, q, b, selectParams...)
+	qs, err := doQuery(ctx, calldepth+1, q, b, selectParams...)
 	if err != nil {
 		return err
 	}
@@ -68,11 +78,33 @@ func doFindTx(calldepth int, q DBTX, b *builder.Builder, dest interface{}) error
 
 		v.Set(reflect.Append(v, appended))
 	}
+	if err := qs.Close(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if isModel && len(opts.preload) != 0 {
+		parents := make([]reflect.Value, v.Len())
+		for i := range parents {
+			parents[i] = reflect.Indirect(v.Index(i))
+		}
+		for _, field := range opts.preload {
+			err := doPreload(ctx, calldepth+1, q, model, parents, field)
+			if err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
 /// Find queries the database with the specified query (b) and fills the specified
 /// slice of struct with their fields using the field name.
-func Find(q DBTX, b *builder.Builder, dest interface{}) error {
-	return doFindTx(1, q, b, dest)
+/// Use Preload in options to load the related models too.
+func Find(q DBTX, b *builder.Builder, dest interface{}, options ...FindOption) error {
+	return doFindTx(context.Background(), 1, q, b, dest, options...)
+}
+
+/// FindContext is like Find but the query is bound to ctx
+func FindContext(ctx context.Context, q DBTX, b *builder.Builder, dest interface{}, options ...FindOption) error {
+	return doFindTx(ctx, 1, q, b, dest, options...)
 }
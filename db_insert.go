@@ -1,18 +1,14 @@
 package sorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/n1xx1/builder"
 	"reflect"
 )
 
-func doInsert(calldepth int, q DBTX, i interface{}) error {
-	var b *builder.Builder
-	if q.Driver() == DriverMssql {
-		b = builder.MsSQL()
-	} else {
-		b = builder.MySQL()
-	}
+func doInsert(ctx context.Context, calldepth int, q DBTX, i interface{}) error {
+	b := q.Driver().Dialect().Builder()
 
 	v := reflect.ValueOf(i)
 	if v.Type().Kind() == reflect.Ptr {
@@ -23,6 +19,11 @@ func doInsert(calldepth int, q DBTX, i interface{}) error {
 	if model == nil {
 		panic("model not found")
 	}
+	ctx = withOperation(ctx, OpInsert, model.ModelName)
+	if err := beforeInsert(q, i); err != nil {
+		return err
+	}
+	setInsertTimes(model, v, q.base().now())
 
 	values := builder.Eq{}
 	for _, f := range model.Fields {
@@ -31,7 +32,8 @@ func doInsert(calldepth int, q DBTX, i interface{}) error {
 		}
 		val := v.FieldByIndex(f.StructFieldPath)
 		if val.Kind() != reflect.Ptr || !val.IsNil() {
-			fieldName := fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)
+			// the columns of an INSERT can't be qualified with the table name on postgres and sqlite
+			fieldName := fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)
 			values[fieldName] = convertToDbType(val)
 		}
 	}
@@ -41,25 +43,36 @@ func doInsert(calldepth int, q DBTX, i interface{}) error {
 		return fmt.Errorf("sql builder: %w", err)
 	}
 
-	sql1 = FormatQuery(q.Driver(), sql1)
-	sql1, args = ConvertQuery(q.Driver(), sql1, args)
+	sql1, args = formatQuery(q, sql1, args)
 
-	var id int64
-	if q.Driver() == DriverMssql {
-		sql1 += "; SELECT ID = CONVERT(BIGINT, SCOPE_IDENTITY())"
+	autoIncrement := model.FieldsWithTag("autoincrement")
+	if len(autoIncrement) == 0 {
+		_, err := timedExec(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		Snapshot(i)
+		return afterInsert(q, i)
+	}
 
-		rows, err := timedQuery(q, sql1, args, calldepth)
+	var id int64
+	sql1, returning := q.Driver().Dialect().InsertReturning(sql1, SqlEscape(q.Driver(), autoIncrement[0].DbName))
+	if returning {
+		rows, err := timedQuery(ctx, q, sql1, args, calldepth)
 		if err != nil {
 			return fmt.Errorf("database error: %w", err)
 		}
-		rows.Next()
+		defer rows.Close()
+		if !rows.Next() {
+			return fmt.Errorf("database error: %w", ErrEmptyResult)
+		}
 
 		err = rows.Scan(&id)
 		if err != nil {
 			return fmt.Errorf("database error: %w", err)
 		}
 	} else {
-		res, err := timedExec(q, sql1, args, calldepth)
+		res, err := timedExec(ctx, q, sql1, args, calldepth)
 		if err != nil {
 			return fmt.Errorf("database error: %w", err)
 		}
@@ -80,9 +93,270 @@ func doInsert(calldepth int, q DBTX, i interface{}) error {
 		}
 	}
 
-	return nil
+	Snapshot(i)
+	return afterInsert(q, i)
 }
 
 func Insert(q DBTX, i interface{}) error {
-	return doInsert(1, q, i)
+	return doInsert(context.Background(), 1, q, i)
+}
+
+func InsertContext(ctx context.Context, q DBTX, i interface{}) error {
+	return doInsert(ctx, 1, q, i)
+}
+
+// insertRow is a row of InsertMany with the fields that are inserted, which are the same ones
+// inserted by Insert, so they depend on the nil pointers of the row
+type insertRow struct {
+	v      reflect.Value
+	fields []*FieldInfo
+	key    string
+	args   []interface{}
+}
+
+func doInsertMany(ctx context.Context, calldepth int, q DBTX, slice interface{}) error {
+	v := reflect.ValueOf(slice)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("slice parameter must be a slice or a pointer to slice")
+	}
+	if v.Len() == 0 {
+		return nil
+	}
+
+	elType := v.Type().Elem()
+	if elType.Kind() == reflect.Ptr {
+		elType = elType.Elem()
+	}
+	model := modelCache[elType]
+	if model == nil {
+		panic("model not found")
+	}
+	ctx = withOperation(ctx, OpInsert, model.ModelName)
+	now := q.base().now()
+
+	rows := make([]insertRow, v.Len())
+	for i := range rows {
+		el := v.Index(i)
+		if el.Kind() == reflect.Ptr {
+			el = el.Elem()
+		}
+		if err := beforeInsert(q, el.Addr().Interface()); err != nil {
+			return err
+		}
+		setInsertTimes(model, el, now)
+		row := insertRow{v: el}
+		key := make([]byte, len(model.Fields))
+		for j, f := range model.Fields {
+			key[j] = '0'
+			if f.IsAutoIncrement {
+				continue
+			}
+			val := el.FieldByIndex(f.StructFieldPath)
+			if val.Kind() != reflect.Ptr || !val.IsNil() {
+				row.fields = append(row.fields, f)
+				row.args = append(row.args, convertToDbType(val))
+				key[j] = '1'
+			}
+		}
+		row.key = string(key)
+		rows[i] = row
+	}
+
+	var chunks [][]insertRow
+	maxParams, maxRows := q.Driver().Dialect().BatchLimits()
+	for len(rows) > 0 {
+		if len(rows[0].fields) == 0 {
+			return fmt.Errorf("model %s has no columns to insert", model.ModelName)
+		}
+		// consecutive rows with the same fields, without exceeding the limits
+		n := 1
+		for n < len(rows) && rows[n].key == rows[0].key {
+			if maxRows > 0 && n+1 > maxRows {
+				break
+			}
+			if maxParams > 0 && (n+1)*len(rows[0].fields) > maxParams {
+				break
+			}
+			n++
+		}
+		chunks = append(chunks, rows[:n])
+		rows = rows[n:]
+	}
+
+	// depth is the calldepth of insertChunks, which depends on the transaction functions in between
+	insertChunks := func(q DBTX, depth int) error {
+		for _, chunk := range chunks {
+			if err := doInsertRows(ctx, depth+1, q, model, chunk); err != nil {
+				return err
+			}
+		}
+		for _, chunk := range chunks {
+			if err := afterInsertRows(q, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(chunks) == 1 {
+		return insertChunks(q, calldepth+1)
+	}
+
+	// many statements are executed inside a transaction (or a savepoint of the current one)
+	// so that either all the rows are inserted or none of them
+	var err error
+	switch t := q.(type) {
+	case *DB:
+		err = t.BeginContext(ctx, func(tx *TX) error {
+			return insertChunks(tx, calldepth+4)
+		})
+	case *TX:
+		err = t.Begin(func(tx *TX) error {
+			return insertChunks(tx, calldepth+3)
+		})
+	default:
+		err = insertChunks(q, calldepth+1)
+	}
+	if err != nil {
+		// the ids of the rolled back rows are not valid
+		for _, f := range model.FieldsWithTag("autoincrement") {
+			for i := 0; i < v.Len(); i++ {
+				el := reflect.Indirect(v.Index(i)).FieldByIndex(f.StructFieldPath)
+				el.Set(reflect.Zero(el.Type()))
+			}
+		}
+	}
+	return err
+}
+
+// doInsertRows inserts rows with a single statement, every row must have the same fields
+func doInsertRows(ctx context.Context, calldepth int, q DBTX, model *ModelInfo, rows []insertRow) error {
+	dialect := q.Driver().Dialect()
+
+	// the columns of an INSERT can't be qualified with the table name on postgres and sqlite
+	columns := make([]string, len(rows[0].fields))
+	for i, f := range rows[0].fields {
+		columns[i] = fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)
+	}
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	var args []interface{}
+	values := make([][]string, len(rows))
+	for i, row := range rows {
+		values[i] = placeholders
+		args = append(args, row.args...)
+	}
+
+	var returning string
+	autoIncrement := model.FieldsWithTag("autoincrement")
+	if len(autoIncrement) != 0 {
+		returning = fmt.Sprintf("[%s.%s]", model.ModelName, autoIncrement[0].Name)
+	}
+
+	table := "[" + model.ModelName + "]"
+	var sql1 string
+	result := InsertManyLastID
+	if len(rows) == 1 {
+		sql1 = insertManyQuery(table, columns, values)
+	} else {
+		sql1, result = dialect.InsertMany(table, columns, values, returning)
+	}
+	sql1, args = formatQuery(q, sql1, args)
+
+	if returning == "" {
+		_, err := timedExec(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		return nil
+	}
+	if len(rows) == 1 {
+		var ok bool
+		if sql1, ok = dialect.InsertReturning(sql1, SqlEscape(q.Driver(), autoIncrement[0].DbName)); ok {
+			result = InsertManyOrdered
+		}
+	}
+
+	ids := make([]int64, len(rows))
+	if result == InsertManyLastID {
+		res, err := timedExec(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		last, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		first := dialect.FirstInsertID(last, len(rows))
+		for i := range ids {
+			ids[i] = first + int64(i)
+		}
+	} else {
+		res, err := timedQuery(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer res.Close()
+		found := make([]bool, len(rows))
+		n := 0
+		for ; res.Next(); n++ {
+			index, id := n, int64(0)
+			if result == InsertManyIndexed {
+				err = res.Scan(&index, &id)
+			} else {
+				err = res.Scan(&id)
+			}
+			if err != nil {
+				return fmt.Errorf("database error: %w", err)
+			}
+			if index < 0 || index >= len(rows) || found[index] {
+				return fmt.Errorf("database error: unexpected inserted row %d", index)
+			}
+			found[index] = true
+			ids[index] = id
+		}
+		if err := res.Err(); err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if n != len(rows) {
+			return fmt.Errorf("database error: %w", ErrEmptyResult)
+		}
+	}
+
+	for i, row := range rows {
+		elem := row.v.FieldByIndex(autoIncrement[0].StructFieldPath)
+		err := setFieldValue(elem, ids[i], nil)
+		if err != nil {
+			return fmt.Errorf("autoincrement decode fail")
+		}
+	}
+	return nil
+}
+
+func afterInsertRows(q DBTX, rows []insertRow) error {
+	for _, row := range rows {
+		i := row.v.Addr().Interface()
+		Snapshot(i)
+		if err := afterInsert(q, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertMany inserts all the elements of slice, which can be a slice of models or of pointers
+// to models, with as few statements as the database allows. The autoincrement field of every
+// element is set to the generated value. When more than one statement is needed they are executed
+// in a transaction, or in a nested one if q is a TX, so either every element is inserted or none.
+func InsertMany(q DBTX, slice interface{}) error {
+	return doInsertMany(context.Background(), 1, q, slice)
+}
+
+func InsertManyContext(ctx context.Context, q DBTX, slice interface{}) error {
+	return doInsertMany(ctx, 1, q, slice)
 }
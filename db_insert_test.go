@@ -0,0 +1,104 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestInsertMany(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	rows := []queryTestModel{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	if err := InsertMany(db, rows); err != nil {
+		t.Fatal(err)
+	}
+	expected := "INSERT INTO `query_test` (`name`) VALUES (?),(?),(?)"
+	if log := fake.log(); len(log) != 1 || log[0] != expected {
+		t.Fatalf("expected %q, got %q", expected, log)
+	}
+	for i, row := range rows {
+		if row.ID != i+1 {
+			t.Errorf("expected id %d, got %d", i+1, row.ID)
+		}
+	}
+}
+
+func TestInsertManyMssql(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMssql)
+	// OUTPUT doesn't guarantee the order, the ids are matched by the index of the row
+	fake.setRows("MERGE INTO", []string{"sorm_rn", "id"},
+		[]driver.Value{int64(1), int64(11)}, []driver.Value{int64(0), int64(10)})
+
+	rows := []*queryTestModel{{Name: "a"}, {Name: "b"}}
+	if err := InsertMany(db, rows); err != nil {
+		t.Fatal(err)
+	}
+	expected := "MERGE INTO [query_test] AS T USING (VALUES (@p1,0),(@p2,1)) AS S([name],sorm_rn) ON 1=0" +
+		" WHEN NOT MATCHED THEN INSERT ([name]) VALUES (S.[name]) OUTPUT S.sorm_rn,INSERTED.[id];"
+	if log := fake.log(); len(log) != 1 || log[0] != expected {
+		t.Fatalf("expected %q, got %q", expected, log)
+	}
+	if rows[0].ID != 10 || rows[1].ID != 11 {
+		t.Fatalf("unexpected ids %d and %d", rows[0].ID, rows[1].ID)
+	}
+
+	// a missing row is an error
+	fake.setRows("MERGE INTO", []string{"sorm_rn", "id"}, []driver.Value{int64(1), int64(11)})
+	if err := InsertMany(db, rows); err == nil {
+		t.Fatal("expected an error for the missing id")
+	}
+}
+
+type insertNoteModel struct {
+	ID   int     `db:"id,primary,autoincrement"`
+	Name string  `db:"name"`
+	Note *string `db:"note"`
+}
+
+func (*insertNoteModel) TableName() string {
+	return "insert_note"
+}
+
+func TestInsertManyRollback(t *testing.T) {
+	AddModel(&insertNoteModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	// the nil note is not inserted, so the rows need two statements and the second one fails
+	note := "note"
+	rows := []insertNoteModel{{Name: "a"}, {Name: "b", Note: &note}}
+	fake.setExecError("`note`", errors.New("failed"))
+	err := InsertMany(db, rows)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	log := fake.log()
+	if len(log) != 4 || log[0] != "BEGIN" || log[3] != "ROLLBACK" {
+		t.Fatalf("expected the statements to be rolled back, got %q", log)
+	}
+	if rows[0].ID != 0 {
+		t.Fatalf("expected no id after the rollback, got %d", rows[0].ID)
+	}
+}
+
+type insertEmptyModel struct {
+	ID int `db:"id,primary,autoincrement"`
+}
+
+func (*insertEmptyModel) TableName() string {
+	return "insert_empty"
+}
+
+func TestInsertManyNoColumns(t *testing.T) {
+	AddModel(&insertEmptyModel{})
+	db, fake := newFakeDB(DriverPostgres)
+
+	if err := InsertMany(db, []insertEmptyModel{{}, {}}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if log := fake.log(); len(log) != 0 {
+		t.Fatalf("expected no statement, got %q", log)
+	}
+}
@@ -1,6 +1,7 @@
 package sorm
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -114,6 +115,9 @@ type QueryScanner struct {
 	dest          []interface{}
 	rows          *sql.Rows
 	cols          []*sql.ColumnType
+	stats         *dbStats
+	// q is passed to the AfterScan of the scanned models
+	q DBTX
 }
 
 /// ScanTo scans every selected thing to a struct using the struct
@@ -157,7 +161,7 @@ func (q *QueryScanner) ScanTo(dest interface{}) error {
 			}
 		}
 	}
-	return nil
+	return afterScan(q.q, dest)
 }
 
 func cacheColumns(columns []*sql.ColumnType, typ reflect.Type, indexes [][]int, dest []interface{}) {
@@ -169,12 +173,27 @@ func cacheColumns(columns []*sql.ColumnType, typ reflect.Type, indexes [][]int,
 		if ok {
 			indexes[i] = f.Index
 		}
-		dest[i] = reflect.New(col.ScanType()).Interface()
+		dest[i] = reflect.New(scanType(col)).Interface()
+	}
+}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// scanType is the ScanType of the column, or interface{} if the driver doesn't know it
+// (like sqlite for the columns without a declared type)
+func scanType(col *sql.ColumnType) reflect.Type {
+	if t := col.ScanType(); t != nil {
+		return t
 	}
+	return interfaceType
 }
 
 func (q *QueryScanner) Next() bool {
-	return q.rows.Next()
+	if !q.rows.Next() {
+		return false
+	}
+	q.stats.addRow()
+	return true
 }
 
 func gatherFindInformation(index int, destSlice interface{}, table *selectedTable) (reflect.Type, bool, error) {
@@ -280,6 +299,10 @@ func (q *QueryScanner) Scan(dest ...interface{}) error {
 		if err != nil {
 			return err
 		}
+		if err := afterScan(q.q, dest[i]); err != nil {
+			return err
+		}
+		Snapshot(dest[i])
 	}
 	return nil
 }
@@ -295,7 +318,9 @@ func (q *QueryScanner) Close() error {
 	return q.rows.Close()
 }
 
-func doQuery(calldepth int, q DBTX, b *builder.Builder, selectParams ...interface{}) (*QueryScanner, error) {
+func doQuery(ctx context.Context, calldepth int, q DBTX, b *builder.Builder, selectParams ...interface{}) (*QueryScanner, error) {
+	ctx = withOperation(ctx, OpQuery, "")
+
 	var selects []*selectedTable
 	var offsets []int
 
@@ -327,10 +352,9 @@ func doQuery(calldepth int, q DBTX, b *builder.Builder, selectParams ...interfac
 		return nil, fmt.Errorf("sql builder error: %w", err)
 	}
 
-	sql1 = FormatQuery(q.Driver(), sql1)
-	sql1, args = ConvertQuery(q.Driver(), sql1, args)
+	sql1, args = formatQuery(q, sql1, args)
 
-	rows, err := timedQuery(q, sql1, args, calldepth)
+	rows, err := timedQuery(ctx, q, sql1, args, calldepth)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -341,15 +365,20 @@ func doQuery(calldepth int, q DBTX, b *builder.Builder, selectParams ...interfac
 	}
 	dest := make([]interface{}, len(rowCols))
 	for i, col := range rowCols {
-		scanType := col.ScanType()
-		dest[i] = reflect.New(scanType).Interface()
+		dest[i] = reflect.New(scanType(col)).Interface()
 	}
 
-	return &QueryScanner{selects: selects, dest: dest, offsets: offsets, rows: rows, cols: rowCols}, nil
+	return &QueryScanner{selects: selects, dest: dest, offsets: offsets, rows: rows, cols: rowCols, stats: q.base().stats, q: q}, nil
 }
 
 /// Query queries the database with the specified query (b) with the models you want
 /// the returned QueryScanner can be used to decode to the interfaces of the models you selected
 func Query(q DBTX, b *builder.Builder, selectParams ...interface{}) (*QueryScanner, error) {
-	return doQuery(1, q, b, selectParams...)
+	return doQuery(context.Background(), 1, q, b, selectParams...)
+}
+
+/// QueryContext is like Query but the query is bound to ctx, which must stay valid
+/// until the returned QueryScanner is closed
+func QueryContext(ctx context.Context, q DBTX, b *builder.Builder, selectParams ...interface{}) (*QueryScanner, error) {
+	return doQuery(ctx, 1, q, b, selectParams...)
 }
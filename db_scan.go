@@ -1,13 +1,14 @@
 package sorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/n1xx1/builder"
 	"reflect"
 )
 
 // basically doFind but for a signle result
-func doScanSingle(calldepth int, q DBTX, b *builder.Builder, dest interface{}) error {
+func doScanSingle(ctx context.Context, calldepth int, q DBTX, b *builder.Builder, dest interface{}) error {
 	elType := reflect.TypeOf(dest)
 	if elType.Kind() != reflect.Ptr {
 		return fmt.Errorf("dest parameter must be a pointer")
@@ -21,9 +22,13 @@ func doScanSingle(calldepth int, q DBTX, b *builder.Builder, dest interface{}) e
 	if isModel {
 		b = b.From("[" + model.ModelName + "]")
 		selectParams = []interface{}{model.ModelName}
+		ctx = withOperation(ctx, OpScan, model.ModelName)
+	} else {
+		ctx = withOperation(ctx, OpScan, "")
 	}
+	b = scopeSoftDelete(q, b)
 
-	qs, err := doQuery(calldepth+1, q, b, selectParams...)
+	qs, err := doQuery(ctx, calldepth+1, q, b, selectParams...)
 	if err != nil {
 		return err
 	}
@@ -46,8 +51,9 @@ func doScanSingle(calldepth int, q DBTX, b *builder.Builder, dest interface{}) e
 	return nil
 }
 
-func doScan(calldepth int, q DBTX, b *builder.Builder, dests ...interface{}) error {
-	qs, err := doQuery(calldepth+1, q, b)
+func doScan(ctx context.Context, calldepth int, q DBTX, b *builder.Builder, dests ...interface{}) error {
+	ctx = withOperation(ctx, OpScan, "")
+	qs, err := doQuery(ctx, calldepth+1, q, scopeSoftDelete(q, b))
 	if err != nil {
 		return err
 	}
@@ -63,8 +69,17 @@ func doScan(calldepth int, q DBTX, b *builder.Builder, dests ...interface{}) err
 // slice of struct with their fields using the field name.
 func Scan(q DBTX, b *builder.Builder, dest ...interface{}) error {
 	if len(dest) == 1 {
-		return doScanSingle(1, q, b, dest[0])
+		return doScanSingle(context.Background(), 1, q, b, dest[0])
+	} else {
+		return doScan(context.Background(), 1, q, b, dest...)
+	}
+}
+
+// ScanContext is like Scan but the query is bound to ctx
+func ScanContext(ctx context.Context, q DBTX, b *builder.Builder, dest ...interface{}) error {
+	if len(dest) == 1 {
+		return doScanSingle(ctx, 1, q, b, dest[0])
 	} else {
-		return doScan(1, q, b, dest...)
+		return doScan(ctx, 1, q, b, dest...)
 	}
 }
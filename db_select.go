@@ -1,18 +1,14 @@
 package sorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/n1xx1/builder"
 	"reflect"
 )
 
-func doSelect(calldepth int, q DBTX, i interface{}) error {
-	var b *builder.Builder
-	if q.Driver() == DriverMssql {
-		b = builder.MsSQL()
-	} else {
-		b = builder.MySQL()
-	}
+func doSelect(ctx context.Context, calldepth int, q DBTX, i interface{}) error {
+	b := q.Driver().Dialect().Builder()
 
 	v := reflect.ValueOf(i)
 	if v.Type().Kind() == reflect.Ptr {
@@ -23,6 +19,7 @@ func doSelect(calldepth int, q DBTX, i interface{}) error {
 	if model == nil {
 		panic("model not found")
 	}
+	ctx = withOperation(ctx, OpSelect, model.ModelName)
 
 	selects := builder.Eq{}
 	for _, f := range model.PrimaryFields {
@@ -31,7 +28,8 @@ func doSelect(calldepth int, q DBTX, i interface{}) error {
 		selects[fieldName] = val.Interface()
 	}
 
-	qs, err := doQuery(calldepth+1, q, b.From("["+model.ModelName+"]").Where(selects), model.ModelName)
+	b = scopeSoftDelete(q, b.From("["+model.ModelName+"]").Where(selects))
+	qs, err := doQuery(ctx, calldepth+1, q, b, model.ModelName)
 	if err != nil {
 		return err
 	}
@@ -46,5 +44,10 @@ func doSelect(calldepth int, q DBTX, i interface{}) error {
 /// if you want to query for zero values for a string, for example, you are supposed to
 /// have *string as the type of the field.
 func Select(q DBTX, i interface{}) error {
-	return doSelect(1, q, i)
+	return doSelect(context.Background(), 1, q, i)
+}
+
+/// SelectContext is like Select but the query is bound to ctx
+func SelectContext(ctx context.Context, q DBTX, i interface{}) error {
+	return doSelect(ctx, 1, q, i)
 }
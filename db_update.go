@@ -1,18 +1,15 @@
 package sorm
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/n1xx1/builder"
 	"reflect"
 )
 
-func doUpdate(calldepth int, q DBTX, i interface{}, otherValues ...builder.Eq) error {
-	var b *builder.Builder
-	if q.Driver() == DriverMssql {
-		b = builder.MsSQL()
-	} else {
-		b = builder.MySQL()
-	}
+func doUpdate(ctx context.Context, calldepth int, q DBTX, i interface{}, otherValues ...builder.Eq) error {
+	b := q.Driver().Dialect().Builder()
 
 	v := reflect.ValueOf(i)
 	if v.Type().Kind() == reflect.Ptr {
@@ -23,44 +20,217 @@ func doUpdate(calldepth int, q DBTX, i interface{}, otherValues ...builder.Eq) e
 	if model == nil {
 		panic("model not found")
 	}
+	ctx = withOperation(ctx, OpUpdate, model.ModelName)
+	if err := beforeUpdate(q, i); err != nil {
+		return err
+	}
 
 	selects := builder.Eq{}
 	values := builder.Eq{}
 	for _, f := range model.Fields {
+		if f.IsVersion {
+			continue
+		}
 		val := v.FieldByIndex(f.StructFieldPath)
-		if reflect.Zero(val.Type()).Interface() != val.Interface() {
-			fieldName := fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)
+		if !val.IsZero() {
 			if f.IsPrimary {
-				selects[fieldName] = convertToDbType(val)
+				selects[fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)] = convertToDbType(val)
 			} else {
-				values[fieldName] = convertToDbType(val)
+				values[fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)] = convertToDbType(val)
 			}
 		}
 	}
+	setUpdateTimes(model, v, q.base().now(), values)
 	for _, eq := range otherValues {
 		for k, v := range eq {
 			values[k] = v
 		}
 	}
+	addVersion(model, v, selects, values)
 
 	sql1, args, err := b.From("[" + model.ModelName + "]").Where(selects).Update(values).ToSQL()
 	if err != nil {
 		return fmt.Errorf("sql builder error: %w", err)
 	}
 
-	sql1 = FormatQuery(q.Driver(), sql1)
-	sql1, args = ConvertQuery(q.Driver(), sql1, args)
+	sql1, args = formatQuery(q, sql1, args)
 
-	_, err = timedExec(q, sql1, args, calldepth)
+	res, err := timedExec(ctx, q, sql1, args, calldepth)
 	if err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
-	return nil
+	if err := checkVersion(model, v, res); err != nil {
+		return err
+	}
+	return afterUpdate(q, i)
 }
 
 // Update updates the row the model represent using it's primary fields for the WHERE
 // and all the non-zero values for the VALUES. Please notice that bool zero value is false,
 // so you should either use *bool in the model or pass custom values for the update.
+// The autoupdatetime fields are always set to the current time.
 func Update(q DBTX, i interface{}, otherValues ...builder.Eq) error {
-	return doUpdate(1, q, i, otherValues...)
+	return doUpdate(context.Background(), 1, q, i, otherValues...)
+}
+
+// UpdateContext is like Update but the query is bound to ctx
+func UpdateContext(ctx context.Context, q DBTX, i interface{}, otherValues ...builder.Eq) error {
+	return doUpdate(ctx, 1, q, i, otherValues...)
+}
+
+func doUpdateFields(ctx context.Context, calldepth int, q DBTX, i interface{}, selectFields func(model *ModelInfo) ([]*FieldInfo, error)) error {
+	b := q.Driver().Dialect().Builder()
+
+	v := reflect.ValueOf(i)
+	if v.Type().Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	model := modelCache[v.Type()]
+	if model == nil {
+		panic("model not found")
+	}
+	ctx = withOperation(ctx, OpUpdate, model.ModelName)
+
+	if len(model.PrimaryFields) == 0 {
+		return ErrMissingWhere
+	}
+	fields, err := selectFields(model)
+	if err != nil {
+		return err
+	}
+	if err := beforeUpdate(q, i); err != nil {
+		return err
+	}
+
+	selects := builder.Eq{}
+	for _, f := range model.PrimaryFields {
+		fieldName := fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)
+		selects[fieldName] = convertToDbType(v.FieldByIndex(f.StructFieldPath))
+	}
+	values := builder.Eq{}
+	for _, f := range fields {
+		// like in Upsert the autocreatetime fields keep the time the row was inserted, and the
+		// softdelete field is only changed by Delete
+		if f.IsPrimary || f.IsAutoIncrement || f.IsVersion || f.IsAutoUpdate || f.IsAutoCreate || f.IsSoftDelete {
+			continue
+		}
+		fieldName := fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)
+		values[fieldName] = convertToDbType(v.FieldByIndex(f.StructFieldPath))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	setUpdateTimes(model, v, q.base().now(), values)
+	addVersion(model, v, selects, values)
+
+	sql1, args, err := b.From("[" + model.ModelName + "]").Where(selects).Update(values).ToSQL()
+	if err != nil {
+		return fmt.Errorf("sql builder error: %w", err)
+	}
+
+	sql1, args = formatQuery(q, sql1, args)
+
+	res, err := timedExec(ctx, q, sql1, args, calldepth)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if err := checkVersion(model, v, res); err != nil {
+		return err
+	}
+	return afterUpdate(q, i)
+}
+
+// addVersion adds the optimistic locking to an update of the model: the row is updated only if
+// it still has the version of the model, which is incremented
+func addVersion(model *ModelInfo, v reflect.Value, selects builder.Eq, values builder.Eq) {
+	f := model.VersionField
+	if f == nil {
+		return
+	}
+	selects[fmt.Sprintf("[!%s.%s]", model.ModelName, f.Name)] = convertToDbType(v.FieldByIndex(f.StructFieldPath))
+	values[fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)] = builder.Incr(1)
+}
+
+// checkVersion returns ErrStaleObject if the update didn't find the row with the version of the
+// model, otherwise it increments the version of the model like the update did
+func checkVersion(model *ModelInfo, v reflect.Value, res sql.Result) error {
+	f := model.VersionField
+	if f == nil {
+		return nil
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n == 0 {
+		return ErrStaleObject
+	}
+
+	val := v.FieldByIndex(f.StructFieldPath)
+	if !val.CanSet() {
+		return nil
+	}
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val.SetInt(val.Int() + 1)
+	default:
+		val.SetUint(val.Uint() + 1)
+	}
+	return nil
+}
+
+func namedFields(names []string) func(model *ModelInfo) ([]*FieldInfo, error) {
+	return func(model *ModelInfo) ([]*FieldInfo, error) {
+		fields := make([]*FieldInfo, len(names))
+		for i, name := range names {
+			fields[i] = model.FieldByName(name)
+			if fields[i] == nil {
+				return nil, fmt.Errorf("field %s not found in model %s", name, model.ModelName)
+			}
+		}
+		return fields, nil
+	}
+}
+
+func allFields(model *ModelInfo) ([]*FieldInfo, error) {
+	return model.Fields, nil
+}
+
+func taggedFields(tags []string) func(model *ModelInfo) ([]*FieldInfo, error) {
+	return func(model *ModelInfo) ([]*FieldInfo, error) {
+		return model.FieldsWithTag(tags...), nil
+	}
+}
+
+// UpdateFields updates the specified fields (by name) of the row the model represent, using
+// all its primary fields for the WHERE. Unlike Update, the values are written even when zero.
+func UpdateFields(q DBTX, i interface{}, fields ...string) error {
+	return doUpdateFields(context.Background(), 1, q, i, namedFields(fields))
+}
+
+// UpdateFieldsContext is like UpdateFields but the query is bound to ctx
+func UpdateFieldsContext(ctx context.Context, q DBTX, i interface{}, fields ...string) error {
+	return doUpdateFields(ctx, 1, q, i, namedFields(fields))
+}
+
+// UpdateAll is like UpdateFields with all the fields that are not primary, autoincrement,
+// autocreatetime or softdelete
+func UpdateAll(q DBTX, i interface{}) error {
+	return doUpdateFields(context.Background(), 1, q, i, allFields)
+}
+
+// UpdateAllContext is like UpdateAll but the query is bound to ctx
+func UpdateAllContext(ctx context.Context, q DBTX, i interface{}) error {
+	return doUpdateFields(ctx, 1, q, i, allFields)
+}
+
+// UpdateTagged is like UpdateFields with the fields having one of the tags (see ModelInfo.FieldsWithTag)
+func UpdateTagged(q DBTX, i interface{}, tags ...string) error {
+	return doUpdateFields(context.Background(), 1, q, i, taggedFields(tags))
+}
+
+// UpdateTaggedContext is like UpdateTagged but the query is bound to ctx
+func UpdateTaggedContext(ctx context.Context, q DBTX, i interface{}, tags ...string) error {
+	return doUpdateFields(ctx, 1, q, i, taggedFields(tags))
 }
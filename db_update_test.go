@@ -0,0 +1,85 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type updateTestModel struct {
+	ID     int    `db:"id,primary,autoincrement"`
+	Name   string `db:"name" dbtags:"editable"`
+	Active bool   `db:"active" dbtags:"editable"`
+	Count  int    `db:"count"`
+}
+
+func (*updateTestModel) TableName() string {
+	return "update_test"
+}
+
+func TestUpdateFields(t *testing.T) {
+	AddModel(&updateTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	m := &updateTestModel{ID: 1}
+	if err := UpdateFields(db, m, "Name", "Count"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateTagged(db, m, "editable"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateAll(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateFields(db, m, "Missing"); err == nil {
+		t.Fatalf("expected an error for the unknown field")
+	}
+
+	expected := []string{
+		"UPDATE `update_test` SET `count`=?,`name`=? WHERE `update_test`.`id`=?",
+		"UPDATE `update_test` SET `active`=?,`name`=? WHERE `update_test`.`id`=?",
+		"UPDATE `update_test` SET `active`=?,`count`=?,`name`=? WHERE `update_test`.`id`=?",
+	}
+	if log := fake.log(); !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+	// the zero values are written too
+	if !reflect.DeepEqual(fake.args[1], []driver.Value{int64(0), "", int64(1)}) {
+		t.Errorf("unexpected args %v", fake.args[1])
+	}
+}
+
+type versionTestModel struct {
+	ID      int    `db:"id,primary"`
+	Name    string `db:"name"`
+	Version int    `db:"version,version"`
+}
+
+func (*versionTestModel) TableName() string {
+	return "version_test"
+}
+
+func TestUpdateVersion(t *testing.T) {
+	AddModel(&versionTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	m := &versionTestModel{ID: 1, Name: "a", Version: 3}
+	if err := Update(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != 4 {
+		t.Fatalf("expected version 4, got %d", m.Version)
+	}
+	expected := "UPDATE `version_test` SET `name`=?,`version`=`version`+? WHERE `version_test`.`id`=? AND `version_test`.`version`=?"
+	if log := fake.log(); len(log) != 1 || log[0] != expected {
+		t.Fatalf("expected %q, got %q", expected, log)
+	}
+
+	fake.setAffected("version_test", 0)
+	if err := UpdateAll(db, m); err != ErrStaleObject {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+	if m.Version != 4 {
+		t.Fatalf("expected the version to be unchanged, got %d", m.Version)
+	}
+}
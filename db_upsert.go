@@ -0,0 +1,147 @@
+package sorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+func doUpsert(ctx context.Context, calldepth int, q DBTX, i interface{}, conflictFields ...string) error {
+	dialect := q.Driver().Dialect()
+
+	v := reflect.ValueOf(i)
+	if v.Type().Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	model := modelCache[v.Type()]
+	if model == nil {
+		panic("model not found")
+	}
+	if model.VersionField != nil {
+		// the version can't be checked by the update of an upsert
+		return fmt.Errorf("model %s has a version field, Upsert is not supported", model.ModelName)
+	}
+
+	conflict := model.PrimaryFields
+	if len(conflictFields) != 0 {
+		conflict = make([]*FieldInfo, len(conflictFields))
+		for j, name := range conflictFields {
+			conflict[j] = model.FieldByName(name)
+			if conflict[j] == nil {
+				return fmt.Errorf("field %s not found in model %s", name, model.ModelName)
+			}
+		}
+	}
+	if len(conflict) == 0 {
+		return fmt.Errorf("model %s has no primary fields", model.ModelName)
+	}
+
+	isConflict := map[*FieldInfo]bool{}
+	for _, f := range conflict {
+		val := v.FieldByIndex(f.StructFieldPath)
+		if f.IsAutoIncrement && val.IsZero() {
+			// the id is not assigned yet, so the row can only be inserted
+			return doInsert(ctx, calldepth+1, q, i)
+		}
+		if val.Kind() == reflect.Ptr && val.IsNil() {
+			return fmt.Errorf("conflict field %s of model %s is nil", f.Name, model.ModelName)
+		}
+		isConflict[f] = true
+	}
+	ctx = withOperation(ctx, OpUpsert, model.ModelName)
+	if err := beforeInsert(q, i); err != nil {
+		return err
+	}
+	setInsertTimes(model, v, q.base().now())
+
+	// the same fields of Insert, plus the autoincrement one since it's not zero
+	var columns, values, conflictColumns, update []string
+	var args []interface{}
+	for _, f := range model.Fields {
+		val := v.FieldByIndex(f.StructFieldPath)
+		if f.IsAutoIncrement && val.IsZero() {
+			continue
+		}
+		if val.Kind() == reflect.Ptr && val.IsNil() {
+			continue
+		}
+		column := fmt.Sprintf("[%s.%s]", model.ModelName, f.Name)
+		args = append(args, convertToDbType(val))
+		columns = append(columns, column)
+		values = append(values, "?")
+		if isConflict[f] {
+			conflictColumns = append(conflictColumns, column)
+		} else if !f.IsAutoIncrement && !f.IsAutoCreate {
+			update = append(update, column)
+		}
+	}
+
+	var returning string
+	autoIncrement := model.FieldsWithTag("autoincrement")
+	if len(autoIncrement) != 0 {
+		returning = fmt.Sprintf("[%s.%s]", model.ModelName, autoIncrement[0].Name)
+	}
+
+	sql1, returns := dialect.Upsert("["+model.ModelName+"]", columns, values, conflictColumns, update, returning)
+	sql1, args = formatQuery(q, sql1, args)
+
+	if returning == "" {
+		_, err := timedExec(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		Snapshot(i)
+		return afterInsert(q, i)
+	}
+
+	var id int64
+	if returns {
+		rows, err := timedQuery(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			return fmt.Errorf("database error: %w", ErrEmptyResult)
+		}
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	} else {
+		res, err := timedExec(ctx, q, sql1, args, calldepth)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		id, err = res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+
+	elem := v.FieldByIndex(autoIncrement[0].StructFieldPath)
+	err := setFieldValue(elem, id, nil)
+	if err != nil {
+		return fmt.Errorf("autoincrement decode fail")
+	}
+	Snapshot(i)
+	return afterInsert(q, i)
+}
+
+// Upsert inserts the model, or updates all its other fields if a row with the same
+// conflictFields (the primary fields by default) already exists. The autoincrement field is
+// set to the value of the inserted or updated row.
+// If the autoincrement field is one of the conflictFields and it's zero, it's just an Insert.
+// Since it's not known in advance whether the row is inserted or updated, the insert hooks
+// (BeforeInserter and AfterInserter) are the ones called in both cases.
+// Models with a version field are not supported, since the update would skip the version check.
+func Upsert(q DBTX, i interface{}, conflictFields ...string) error {
+	return doUpsert(context.Background(), 1, q, i, conflictFields...)
+}
+
+// UpsertContext is like Upsert but the query is bound to ctx
+func UpsertContext(ctx context.Context, q DBTX, i interface{}, conflictFields ...string) error {
+	return doUpsert(ctx, 1, q, i, conflictFields...)
+}
@@ -0,0 +1,72 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	AddModel(&queryTestModel{})
+
+	tests := []struct {
+		driver   Driver
+		expected string
+	}{
+		{DriverMysql, "INSERT INTO `query_test` (`id`,`name`) VALUES (?,?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`),`id`=LAST_INSERT_ID(`id`)"},
+		{DriverPostgres, `INSERT INTO "query_test" ("id","name") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name" RETURNING "id"`},
+		{DriverMssql, "MERGE INTO [query_test] WITH (HOLDLOCK) AS T USING (SELECT @p1 AS [id],@p2 AS [name]) AS S ON T.[id]=S.[id]" +
+			" WHEN MATCHED THEN UPDATE SET T.[name]=S.[name] WHEN NOT MATCHED THEN INSERT ([name]) VALUES (S.[name]) OUTPUT INSERTED.[id];"},
+	}
+	for _, test := range tests {
+		db, fake := newFakeDB(test.driver)
+		fake.setRows("query_test", []string{"id"}, []driver.Value{int64(5)})
+
+		m := &queryTestModel{ID: 5, Name: "a"}
+		if err := Upsert(db, m); err != nil {
+			t.Fatal(err)
+		}
+		if log := fake.log(); len(log) != 1 || log[0] != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.driver, test.expected, log)
+		}
+		// the LastInsertId of the fake is a counter, so only the returned id can be checked
+		if test.driver != DriverMysql && m.ID != 5 {
+			t.Errorf("%s: expected id 5, got %d", test.driver, m.ID)
+		}
+	}
+
+	// without an id it's a plain insert
+	db, fake := newFakeDB(DriverMysql)
+	if err := Upsert(db, &queryTestModel{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "INSERT INTO `query_test` (`name`) Values (?)"
+	if log := fake.log(); len(log) != 1 || log[0] != expected {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+}
+
+func TestUpsertNilConflict(t *testing.T) {
+	AddModel(&insertNoteModel{})
+	db, fake := newFakeDB(DriverPostgres)
+
+	if err := Upsert(db, &insertNoteModel{Name: "a"}, "Note"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if log := fake.log(); len(log) != 0 {
+		t.Fatalf("expected no statement, got %q", log)
+	}
+}
+
+func TestUpsertVersion(t *testing.T) {
+	AddModel(&versionTestModel{})
+	db, fake := newFakeDB(DriverPostgres)
+
+	for _, m := range []*versionTestModel{{}, {ID: 1, Version: 1}} {
+		if err := Upsert(db, m); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if log := fake.log(); len(log) != 0 {
+		t.Fatalf("expected no statement, got %q", log)
+	}
+}
@@ -0,0 +1,107 @@
+package sorm
+
+import (
+	"fmt"
+	"github.com/n1xx1/builder"
+	"strings"
+)
+
+// Dialect contains everything that is specific to a database, every Driver is backed by one.
+// Custom dialects can be added with RegisterDialect.
+type Dialect interface {
+	// Name returns the name of the dialect, used in error messages
+	Name() string
+	// Builder returns a new builder configured for the database
+	Builder() *builder.Builder
+	// Quote escapes an identifier (a table or a column name)
+	Quote(identifier string) string
+	// ConvertQuery rewrites the @p1..@pN placeholders produced by FormatQuery to the ones
+	// expected by the database driver, reordering args if needed
+	ConvertQuery(query string, args []interface{}) (string, []interface{})
+	// InsertReturning rewrites an INSERT statement so that it returns the value generated for
+	// the autoincrement column (already quoted) as a single row. If it returns false the
+	// statement is executed as is and the value is obtained with sql.Result.LastInsertId
+	InsertReturning(query string, column string) (string, bool)
+	// InsertMany returns a statement inserting many rows in table, rows are the placeholders of
+	// columns. Like in Upsert, the table and the columns are written as [Model] and [Model.Field].
+	// If returning is not empty it's the autoincrement column and the result tells how the
+	// generated values are obtained.
+	InsertMany(table string, columns []string, rows [][]string, returning string) (string, InsertManyResult)
+	// FirstInsertID returns the value generated for the first of the rows inserted by a single
+	// statement, given the sql.Result.LastInsertId of the statement. It's used only when
+	// InsertMany returns InsertManyLastID, assuming the values are consecutive
+	FirstInsertID(lastInsertID int64, rows int) int64
+	// Upsert returns a statement inserting a row in table, or updating the update columns of the
+	// existing row with the same conflict columns. values are the placeholders of columns, the
+	// table and the columns are written as [Model] and [Model.Field], since the statement is
+	// passed to FormatQuery like every other one. If returning is not empty it's the autoincrement column,
+	// which must be returned as a single row for both the inserted and the updated row, unless
+	// false is returned, in which case its value is obtained with sql.Result.LastInsertId
+	Upsert(table string, columns []string, values []string, conflict []string, update []string, returning string) (string, bool)
+	// BatchLimits returns the maximum number of parameters and of rows of a single statement,
+	// zero if there is no limit
+	BatchLimits() (maxParams int, maxRows int)
+	// Limit adds the limit and offset to a select
+	Limit(b *builder.Builder, limit int, offset int) *builder.Builder
+	// Macro renders the database specific macros (MIN!, MAX!, ADDMONTH!) with already
+	// validated arguments, it returns false when the macro is not supported
+	Macro(name string, args []string) (string, bool)
+	// Savepoint returns the statement creating a savepoint inside a transaction
+	Savepoint(name string) string
+	// RollbackSavepoint returns the statement rolling back to a savepoint
+	RollbackSavepoint(name string) string
+	// ReleaseSavepoint returns the statement releasing a savepoint, or an empty string if
+	// the database doesn't need it
+	ReleaseSavepoint(name string) string
+	// IsRetryable reports whether err is a transient error, like a deadlock, after which the
+	// transaction can be retried
+	IsRetryable(err error) bool
+}
+
+// InsertManyResult is how the values generated by the statement of Dialect.InsertMany are obtained
+type InsertManyResult int
+
+const (
+	// InsertManyLastID is sql.Result.LastInsertId, passed to Dialect.FirstInsertID
+	InsertManyLastID InsertManyResult = iota
+	// InsertManyOrdered is a row with the value for every inserted row, in the same order
+	InsertManyOrdered
+	// InsertManyIndexed is a row with the index of the inserted row and its value for every
+	// inserted row, in any order
+	InsertManyIndexed
+)
+
+// insertManyQuery is the INSERT of many rows with a VALUES list
+func insertManyQuery(table string, columns []string, rows [][]string) string {
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i] = "(" + strings.Join(row, ",") + ")"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, strings.Join(columns, ","), strings.Join(values, ","))
+}
+
+var dialects = []Dialect{
+	DriverMysql:    mysqlDialect{},
+	DriverMssql:    mssqlDialect{},
+	DriverPostgres: postgresDialect{},
+	DriverSqlite:   sqliteDialect{},
+}
+
+// RegisterDialect adds a new dialect and returns the Driver to pass to Open in order to use it.
+// It's not safe for concurrent use, so it's supposed to be called during initialization.
+func RegisterDialect(dialect Dialect) Driver {
+	dialects = append(dialects, dialect)
+	return Driver(len(dialects) - 1)
+}
+
+// Dialect returns the dialect backing the driver
+func (d Driver) Dialect() Dialect {
+	if d < 0 || int(d) >= len(dialects) {
+		panic(fmt.Sprintf("unknown driver %d", int(d)))
+	}
+	return dialects[d]
+}
+
+func (d Driver) String() string {
+	return d.Dialect().Name()
+}
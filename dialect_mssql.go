@@ -0,0 +1,143 @@
+package sorm
+
+import (
+	"errors"
+	"fmt"
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/n1xx1/builder"
+	"strings"
+)
+
+type mssqlDialect struct{}
+
+func (mssqlDialect) Name() string {
+	return "mssql"
+}
+
+func (mssqlDialect) Builder() *builder.Builder {
+	return builder.MsSQL()
+}
+
+func (mssqlDialect) Quote(identifier string) string {
+	return "[" + identifier + "]"
+}
+
+func (mssqlDialect) ConvertQuery(query string, args []interface{}) (string, []interface{}) {
+	// @pN are the native placeholders
+	return query, args
+}
+
+func (mssqlDialect) InsertReturning(query string, column string) (string, bool) {
+	return query + "; SELECT ID = CONVERT(BIGINT, SCOPE_IDENTITY())", true
+}
+
+func (mssqlDialect) InsertMany(table string, columns []string, rows [][]string, returning string) (string, InsertManyResult) {
+	if returning == "" {
+		return insertManyQuery(table, columns, rows), InsertManyLastID
+	}
+	// SCOPE_IDENTITY only returns the last value and OUTPUT doesn't guarantee the order of the
+	// rows, so every row gets its index, which MERGE (unlike INSERT) can output with the value
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i] = fmt.Sprintf("(%s,%d)", strings.Join(row, ","), i)
+	}
+	source := make([]string, len(columns))
+	for i, c := range columns {
+		source[i] = "S." + c
+	}
+	return fmt.Sprintf("MERGE INTO %s AS T USING (VALUES %s) AS S(%s,sorm_rn) ON 1=0 WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s) OUTPUT S.sorm_rn,INSERTED.%s;",
+		table, strings.Join(values, ","), strings.Join(columns, ","), strings.Join(columns, ","), strings.Join(source, ","), returning), InsertManyIndexed
+}
+
+func (mssqlDialect) FirstInsertID(lastInsertID int64, rows int) int64 {
+	return lastInsertID
+}
+
+func (mssqlDialect) BatchLimits() (int, int) {
+	// 2100 parameters, including the statement and the parameter definitions passed to
+	// sp_executesql by the driver, and 1000 rows in a VALUES list
+	return 2098, 1000
+}
+
+func (mssqlDialect) Upsert(table string, columns []string, values []string, conflict []string, update []string, returning string) (string, bool) {
+	source := make([]string, len(columns))
+	for i, c := range columns {
+		source[i] = fmt.Sprintf("%s AS %s", values[i], c)
+	}
+	on := make([]string, len(conflict))
+	for i, c := range conflict {
+		on[i] = fmt.Sprintf("T.%s=S.%s", c, c)
+	}
+	// the identity column can't be inserted or updated explicitly
+	var inserted, insertedValues []string
+	for _, c := range columns {
+		if c != returning {
+			inserted = append(inserted, c)
+			insertedValues = append(insertedValues, "S."+c)
+		}
+	}
+	if len(update) == 0 && returning != "" && len(inserted) != 0 {
+		// without WHEN MATCHED the existing row wouldn't be returned by OUTPUT
+		update = inserted[:1]
+	}
+	set := make([]string, len(update))
+	for i, c := range update {
+		set[i] = fmt.Sprintf("T.%s=S.%s", c, c)
+	}
+
+	// HOLDLOCK avoids a concurrent insert of the same row between the match and the insert
+	query := fmt.Sprintf("MERGE INTO %s WITH (HOLDLOCK) AS T USING (SELECT %s) AS S ON %s",
+		table, strings.Join(source, ","), strings.Join(on, " AND "))
+	if len(set) != 0 {
+		query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(set, ",")
+	}
+	if len(inserted) == 0 {
+		query += " WHEN NOT MATCHED THEN INSERT DEFAULT VALUES"
+	} else {
+		query += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)", strings.Join(inserted, ","), strings.Join(insertedValues, ","))
+	}
+	if returning == "" {
+		return query + ";", false
+	}
+	return query + " OUTPUT INSERTED." + returning + ";", true
+}
+
+func (mssqlDialect) Limit(b *builder.Builder, limit int, offset int) *builder.Builder {
+	return b.Limit(limit, offset)
+}
+
+func (mssqlDialect) Savepoint(name string) string {
+	return "SAVE TRANSACTION " + name
+}
+
+func (mssqlDialect) RollbackSavepoint(name string) string {
+	return "ROLLBACK TRANSACTION " + name
+}
+
+func (mssqlDialect) ReleaseSavepoint(name string) string {
+	// mssql savepoints can't be released, they last until the end of the transaction
+	return ""
+}
+
+func (mssqlDialect) IsRetryable(err error) bool {
+	// 1205: chosen as deadlock victim, 1222: lock request time out period exceeded
+	var mssqlErr mssql.Error
+	if errors.As(err, &mssqlErr) {
+		return mssqlErr.Number == 1205 || mssqlErr.Number == 1222
+	}
+	return false
+}
+
+func (mssqlDialect) Macro(name string, args []string) (string, bool) {
+	switch name {
+	case "MIN":
+		values := "(" + strings.Join(args, "),(") + ")"
+		return fmt.Sprintf("(SELECT MIN(i) FROM (VALUES %s) AS T(i))", values), true
+	case "MAX":
+		values := "(" + strings.Join(args, "),(") + ")"
+		return fmt.Sprintf("(SELECT MAX(i) FROM (VALUES %s) AS T(i))", values), true
+	case "ADDMONTH":
+		return fmt.Sprintf("DATEADD(month, %s, %s)", args[1], args[0]), true
+	}
+	return "", false
+}
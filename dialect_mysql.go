@@ -0,0 +1,114 @@
+package sorm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/n1xx1/builder"
+	"strconv"
+	"strings"
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Builder() *builder.Builder {
+	return builder.MySQL()
+}
+
+func (mysqlDialect) Quote(identifier string) string {
+	return "`" + identifier + "`"
+}
+
+func (mysqlDialect) ConvertQuery(query string, args []interface{}) (string, []interface{}) {
+	return convertQuestionMarks(query, args)
+}
+
+func (mysqlDialect) InsertReturning(query string, column string) (string, bool) {
+	return query, false
+}
+
+func (mysqlDialect) InsertMany(table string, columns []string, rows [][]string, returning string) (string, InsertManyResult) {
+	return insertManyQuery(table, columns, rows), InsertManyLastID
+}
+
+func (mysqlDialect) FirstInsertID(lastInsertID int64, rows int) int64 {
+	// LAST_INSERT_ID() is the value generated for the first inserted row, the following ones are
+	// consecutive unless innodb_autoinc_lock_mode is 2 and there are concurrent inserts
+	return lastInsertID
+}
+
+func (mysqlDialect) BatchLimits() (int, int) {
+	// the number of placeholders of a prepared statement is sent as a uint16
+	return 65535, 0
+}
+
+func (mysqlDialect) Upsert(table string, columns []string, values []string, conflict []string, update []string, returning string) (string, bool) {
+	// mysql checks every unique key, so conflict is not needed
+	var set []string
+	for _, c := range update {
+		set = append(set, fmt.Sprintf("%s=VALUES(%s)", c, c))
+	}
+	if returning != "" {
+		// makes LastInsertId return the id of the updated row too
+		set = append(set, fmt.Sprintf("%s=LAST_INSERT_ID(%s)", returning, returning))
+	}
+	if len(set) == 0 {
+		set = append(set, fmt.Sprintf("%s=%s", columns[0], columns[0]))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
+		table, strings.Join(columns, ","), strings.Join(values, ","), strings.Join(set, ",")), false
+}
+
+func (mysqlDialect) Limit(b *builder.Builder, limit int, offset int) *builder.Builder {
+	return b.Limit(limit, offset)
+}
+
+func (mysqlDialect) Savepoint(name string) string {
+	return "SAVEPOINT " + name
+}
+
+func (mysqlDialect) RollbackSavepoint(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (mysqlDialect) ReleaseSavepoint(name string) string {
+	return "RELEASE SAVEPOINT " + name
+}
+
+func (mysqlDialect) IsRetryable(err error) bool {
+	// 1213: deadlock found, 1205: lock wait timeout exceeded
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	return false
+}
+
+func (mysqlDialect) Macro(name string, args []string) (string, bool) {
+	switch name {
+	case "MIN":
+		return fmt.Sprintf("LEAST(%s)", strings.Join(args, ",")), true
+	case "MAX":
+		values := "SELECT " + args[0] + " AS i UNION SELECT " + strings.Join(args[1:], " UNION SELECT ")
+		return fmt.Sprintf("(SELECT MAX(v.i) FROM (%s) v)", values), true
+	case "ADDMONTH":
+		return fmt.Sprintf("DATE_ADD(%s, INTERVAL %s MONTH)", args[0], args[1]), true
+	}
+	return "", false
+}
+
+// convertQuestionMarks replaces the @pN placeholders with ?, since they are not numbered
+// the args are reordered (and repeated) as they appear in the query
+func convertQuestionMarks(query string, args []interface{}) (string, []interface{}) {
+	realArgs := make([]interface{}, 0, len(args))
+	query = ReplaceAllStringSubmatchFunc(regexParamMs, query, func(groups []string) string {
+		pos, _ := strconv.ParseInt(groups[1], 10, 32)
+		realArgs = append(realArgs, args[pos-1])
+		return "?"
+	})
+	return query, realArgs
+}
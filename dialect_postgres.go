@@ -0,0 +1,123 @@
+package sorm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/n1xx1/builder"
+	"strings"
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) Builder() *builder.Builder {
+	return builder.Postgres()
+}
+
+func (postgresDialect) Quote(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+func (postgresDialect) ConvertQuery(query string, args []interface{}) (string, []interface{}) {
+	// postgres placeholders are positional too, so the args are already in the right order
+	return regexParamMs.ReplaceAllString(query, "$$$1"), args
+}
+
+func (postgresDialect) InsertReturning(query string, column string) (string, bool) {
+	// postgres has no LastInsertId, the generated value is returned by the statement itself
+	return query + " RETURNING " + column, true
+}
+
+func (postgresDialect) InsertMany(table string, columns []string, rows [][]string, returning string) (string, InsertManyResult) {
+	query := insertManyQuery(table, columns, rows)
+	if returning == "" {
+		return query, InsertManyLastID
+	}
+	// the rows of RETURNING are in the order of VALUES
+	return query + " RETURNING " + returning, InsertManyOrdered
+}
+
+func (postgresDialect) FirstInsertID(lastInsertID int64, rows int) int64 {
+	return lastInsertID
+}
+
+func (postgresDialect) BatchLimits() (int, int) {
+	// the number of parameters is sent as a uint16
+	return 65535, 0
+}
+
+func (postgresDialect) Upsert(table string, columns []string, values []string, conflict []string, update []string, returning string) (string, bool) {
+	return onConflictUpsert(table, columns, values, conflict, update, returning)
+}
+
+// onConflictUpsert is the upsert with INSERT ... ON CONFLICT, shared by postgres and sqlite
+func onConflictUpsert(table string, columns []string, values []string, conflict []string, update []string, returning string) (string, bool) {
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) ",
+		table, strings.Join(columns, ","), strings.Join(values, ","), strings.Join(conflict, ","))
+
+	if len(update) == 0 && returning != "" {
+		// DO NOTHING wouldn't return the existing row
+		update = conflict[:1]
+	}
+	if len(update) == 0 {
+		query += "DO NOTHING"
+	} else {
+		set := make([]string, len(update))
+		for i, c := range update {
+			set[i] = fmt.Sprintf("%s=EXCLUDED.%s", c, c)
+		}
+		query += "DO UPDATE SET " + strings.Join(set, ",")
+	}
+
+	if returning == "" {
+		return query, false
+	}
+	return query + " RETURNING " + returning, true
+}
+
+func (postgresDialect) Limit(b *builder.Builder, limit int, offset int) *builder.Builder {
+	return b.Limit(limit, offset)
+}
+
+func (postgresDialect) Savepoint(name string) string {
+	return "SAVEPOINT " + name
+}
+
+func (postgresDialect) RollbackSavepoint(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (postgresDialect) ReleaseSavepoint(name string) string {
+	return "RELEASE SAVEPOINT " + name
+}
+
+func (postgresDialect) IsRetryable(err error) bool {
+	// no driver is imported, so the SQLSTATE is read with the method exposed by both lib/pq
+	// and pgx errors. 40001: serialization_failure, 40P01: deadlock_detected, 55P03: lock_not_available
+	var pgErr interface {
+		SQLState() string
+	}
+	if errors.As(err, &pgErr) {
+		switch pgErr.SQLState() {
+		case "40001", "40P01", "55P03":
+			return true
+		}
+	}
+	return false
+}
+
+func (postgresDialect) Macro(name string, args []string) (string, bool) {
+	switch name {
+	case "MIN":
+		return fmt.Sprintf("LEAST(%s)", strings.Join(args, ",")), true
+	case "MAX":
+		// unlike mysql, postgres GREATEST ignores NULL values like the aggregate MAX does
+		return fmt.Sprintf("GREATEST(%s)", strings.Join(args, ",")), true
+	case "ADDMONTH":
+		return fmt.Sprintf("(%s + (%s) * INTERVAL '1 month')", args[0], args[1]), true
+	}
+	return "", false
+}
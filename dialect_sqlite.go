@@ -0,0 +1,91 @@
+package sorm
+
+import (
+	"fmt"
+	"github.com/n1xx1/builder"
+	"strconv"
+	"strings"
+)
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) Builder() *builder.Builder {
+	return builder.SQLite()
+}
+
+func (sqliteDialect) Quote(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+func (sqliteDialect) ConvertQuery(query string, args []interface{}) (string, []interface{}) {
+	return convertQuestionMarks(query, args)
+}
+
+func (sqliteDialect) InsertReturning(query string, column string) (string, bool) {
+	// LastInsertId is the last_insert_rowid() of the connection that ran the insert, which a
+	// separate SELECT last_insert_rowid() could not guarantee when running outside of a transaction
+	return query, false
+}
+
+func (sqliteDialect) InsertMany(table string, columns []string, rows [][]string, returning string) (string, InsertManyResult) {
+	return insertManyQuery(table, columns, rows), InsertManyLastID
+}
+
+func (sqliteDialect) FirstInsertID(lastInsertID int64, rows int) int64 {
+	// last_insert_rowid() is the rowid of the last inserted row
+	return lastInsertID - int64(rows) + 1
+}
+
+func (sqliteDialect) BatchLimits() (int, int) {
+	// SQLITE_MAX_VARIABLE_NUMBER defaults to 999 before 3.32.0
+	return 999, 0
+}
+
+func (sqliteDialect) Upsert(table string, columns []string, values []string, conflict []string, update []string, returning string) (string, bool) {
+	// LastInsertId doesn't change when the row is updated, so RETURNING (sqlite 3.35) is needed
+	return onConflictUpsert(table, columns, values, conflict, update, returning)
+}
+
+func (sqliteDialect) Limit(b *builder.Builder, limit int, offset int) *builder.Builder {
+	return b.Limit(limit, offset)
+}
+
+func (sqliteDialect) Savepoint(name string) string {
+	return "SAVEPOINT " + name
+}
+
+func (sqliteDialect) RollbackSavepoint(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (sqliteDialect) ReleaseSavepoint(name string) string {
+	return "RELEASE SAVEPOINT " + name
+}
+
+func (sqliteDialect) IsRetryable(err error) bool {
+	// SQLITE_BUSY and SQLITE_LOCKED, matched by message since no driver is imported
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
+}
+
+func (sqliteDialect) Macro(name string, args []string) (string, bool) {
+	switch name {
+	case "MIN":
+		return fmt.Sprintf("min(%s)", strings.Join(args, ",")), true
+	case "MAX":
+		return fmt.Sprintf("max(%s)", strings.Join(args, ",")), true
+	case "ADDMONTH":
+		if n, err := strconv.Atoi(args[1]); err == nil {
+			return fmt.Sprintf("date(%s, '%+d months')", args[0], n), true
+		}
+		return fmt.Sprintf("date(%s, (%s) || ' months')", args[0], args[1]), true
+	}
+	return "", false
+}
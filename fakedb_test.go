@@ -0,0 +1,254 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+)
+
+// fakeDB is an in memory database/sql driver used by the tests: it records every statement
+// (and BEGIN/COMMIT/ROLLBACK) and answers queries with the rows set with setRows
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	results    map[string]fakeResult
+	execErr    map[string]error
+	prepareErr map[string]error
+	affected   map[string]int64
+	lastID     int64
+	prepared   int
+	closed     int
+}
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// newFakeDB opens a DB backed by a new fakeDB, without the periodic ping
+func newFakeDB(driver Driver, options ...OpenOption) (*DB, *fakeDB) {
+	sqldb, f := newFakeSQLDB()
+	db := Open(sqldb, driver, append([]OpenOption{WithoutPing()}, options...)...)
+	return db, f
+}
+
+func newFakeSQLDB() (*sql.DB, *fakeDB) {
+	f := &fakeDB{results: map[string]fakeResult{}, execErr: map[string]error{}, prepareErr: map[string]error{}, affected: map[string]int64{}}
+	return sql.OpenDB(f), f
+}
+
+// setRows sets the result of the queries containing the substring match
+func (f *fakeDB) setRows(match string, columns []string, rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results[match] = fakeResult{columns, rows}
+}
+
+// setExecError makes the statements containing the substring match fail with err, a nil
+// err removes the failure
+func (f *fakeDB) setExecError(match string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.execErr, match)
+		return
+	}
+	f.execErr[match] = err
+}
+
+// setPrepareError makes the prepare of the statements containing the substring match fail with err
+func (f *fakeDB) setPrepareError(match string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prepareErr[match] = err
+}
+
+// setAffected sets the rows affected by the statements containing the substring match, 1 by default
+func (f *fakeDB) setAffected(match string, n int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.affected[match] = n
+}
+
+func (f *fakeDB) log() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.statements...)
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	f.statements = append(f.statements, query)
+	f.args = append(f.args, values)
+}
+
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{f}, nil
+}
+
+func (f *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	f *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	for match, err := range c.f.prepareErr {
+		if strings.Contains(query, match) {
+			return nil, err
+		}
+	}
+	c.f.prepared++
+	return &fakeStmt{c, query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		c.f.record("BEGIN READ ONLY", nil)
+	} else {
+		c.f.record("BEGIN", nil)
+	}
+	return &fakeTx{c.f}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.f.record(query, args)
+
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	for match, err := range c.f.execErr {
+		if strings.Contains(query, match) {
+			return nil, err
+		}
+	}
+	c.f.lastID++
+	affected := int64(1)
+	for match, n := range c.f.affected {
+		if strings.Contains(query, match) {
+			affected = n
+		}
+	}
+	return fakeExecResult{c.f.lastID, affected}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.f.record(query, args)
+
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	for match, err := range c.f.execErr {
+		if strings.Contains(query, match) {
+			return nil, err
+		}
+	}
+	for match, res := range c.f.results {
+		if strings.Contains(query, match) {
+			return &fakeRows{columns: res.columns, rows: res.rows}, nil
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeExecResult struct {
+	id       int64
+	affected int64
+}
+
+func (r fakeExecResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r fakeExecResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+type fakeTx struct {
+	f *fakeDB
+}
+
+func (t *fakeTx) Commit() error {
+	t.f.record("COMMIT", nil)
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.f.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+// fakeStmt executes the statement on the connection when run, counting prepares and closes
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	s.c.f.mu.Lock()
+	defer s.c.f.mu.Unlock()
+	s.c.f.closed++
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.c.ExecContext(ctx, s.query, args)
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.c.QueryContext(ctx, s.query, args)
+}
@@ -5,6 +5,7 @@ go 1.13
 require (
 	github.com/denisenkom/go-mssqldb v0.0.0-20191001013358-cfbb681360f0
 	github.com/go-sql-driver/mysql v1.4.1
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/n1xx1/builder v0.3.5-0.20190612101549-e2e4763d15c4
 	github.com/pkg/errors v0.8.1
 )
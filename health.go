@@ -0,0 +1,99 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// HealthStatus is the state of the periodic ping of a DB
+type HealthStatus struct {
+	// LastPing is the time the last ping completed, zero if no ping was done yet
+	LastPing time.Time
+	// LastError is the error returned by the last ping, nil if it succeeded
+	LastError error
+	// ConsecutiveFailures counts the pings failed since the last successful one
+	ConsecutiveFailures int
+	// Latency is how long the last ping took
+	Latency time.Duration
+}
+
+type healthChecker struct {
+	db       *sql.DB
+	interval time.Duration
+	handler  func(err error)
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	mu     sync.Mutex
+	status HealthStatus
+}
+
+func startHealthChecker(db *sql.DB, interval time.Duration, handler func(err error)) *healthChecker {
+	h := &healthChecker{
+		db:       db,
+		interval: interval,
+		handler:  handler,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *healthChecker) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.ping()
+		}
+	}
+}
+
+func (h *healthChecker) ping() {
+	// a ping can't last more than the interval, otherwise they would start piling up
+	ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+	start := time.Now()
+	err := h.db.PingContext(ctx)
+	latency := time.Since(start)
+	cancel()
+
+	h.mu.Lock()
+	h.status.LastPing = time.Now()
+	h.status.Latency = latency
+	h.status.LastError = err
+	if err != nil {
+		h.status.ConsecutiveFailures++
+	} else {
+		h.status.ConsecutiveFailures = 0
+	}
+	h.mu.Unlock()
+
+	if h.handler != nil {
+		h.handler(err)
+	}
+}
+
+func (h *healthChecker) Status() HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Stop stops the checker and waits for the running ping, if any, to complete
+func (h *healthChecker) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+	<-h.done
+}
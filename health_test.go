@@ -0,0 +1,124 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pingDriver is a database/sql driver that only supports pinging, failing while failing is set
+type pingDriver struct {
+	failing int32
+}
+
+type pingConn struct {
+	d *pingDriver
+}
+
+func (d *pingDriver) Open(name string) (driver.Conn, error) {
+	return &pingConn{d}, nil
+}
+
+func (c *pingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *pingConn) Close() error {
+	return nil
+}
+
+func (c *pingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *pingConn) Ping(ctx context.Context) error {
+	if atomic.LoadInt32(&c.d.failing) != 0 {
+		return errors.New("ping failed")
+	}
+	return nil
+}
+
+var testPingDriver = &pingDriver{}
+
+func init() {
+	sql.Register("sorm-ping", testPingDriver)
+}
+
+func TestHealthChecker(t *testing.T) {
+	sqldb, err := sql.Open("sorm-ping", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pings := make(chan error, 16)
+	db := Open(sqldb, DriverMysql, WithPingInterval(time.Millisecond), WithPingHandler(func(err error) {
+		select {
+		case pings <- err:
+		default:
+		}
+	}))
+
+	atomic.StoreInt32(&testPingDriver.failing, 1)
+	defer atomic.StoreInt32(&testPingDriver.failing, 0)
+
+	failures := 0
+	for failures < 2 {
+		if err := <-pings; err != nil {
+			failures++
+		}
+	}
+
+	status := db.Health()
+	if status.LastError == nil || status.ConsecutiveFailures < 2 || status.LastPing.IsZero() {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the checker is stopped, so the status can't change anymore
+	status = db.Health()
+	time.Sleep(time.Millisecond * 5)
+	if db.Health() != status {
+		t.Fatalf("status changed after Close")
+	}
+}
+
+func TestWithoutPing(t *testing.T) {
+	sqldb, err := sql.Open("sorm-ping", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := Open(sqldb, DriverMysql, WithoutPing())
+	if db.health != nil {
+		t.Fatalf("expected no health checker")
+	}
+	if !db.Health().LastPing.IsZero() {
+		t.Fatalf("expected zero health status")
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPingIntervalInvalid(t *testing.T) {
+	sqldb, err := sql.Open("sorm-ping", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a zero interval would make time.NewTicker panic
+	db := Open(sqldb, DriverMysql, WithPingInterval(0))
+	if db.health.interval != defaultPingInterval {
+		t.Errorf("expected the default interval, got %v", db.health.interval)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,124 @@
+package sorm
+
+import (
+	"context"
+	"time"
+)
+
+// Operation is the kind of operation performed by sorm
+type Operation string
+
+const (
+	OpQuery    Operation = "query"
+	OpFind     Operation = "find"
+	OpScan     Operation = "scan"
+	OpSelect   Operation = "select"
+	OpCount    Operation = "count"
+	OpInsert   Operation = "insert"
+	OpUpdate   Operation = "update"
+	OpUpsert   Operation = "upsert"
+	OpDelete   Operation = "delete"
+	OpExec     Operation = "exec"
+	OpBegin    Operation = "begin"
+	OpCommit   Operation = "commit"
+	OpRollback Operation = "rollback"
+)
+
+// HookEvent describes an operation observed by a Hook
+type HookEvent struct {
+	Operation Operation
+	// Model is the name of the model the operation works on, empty if there isn't one
+	Model string
+	// SQL is the executed statement, empty for Begin, Commit and Rollback
+	SQL string
+	// Duration and Err are set only when passed to Hook.After
+	Duration time.Duration
+	Err      error
+}
+
+// Hook is called around every statement executed by sorm and every Begin, Commit and Rollback.
+// Before returns the context used for the operation and passed to After, so it can be used
+// to start a span or to carry anything else to After.
+type Hook interface {
+	Before(ctx context.Context, e *HookEvent) context.Context
+	After(ctx context.Context, e *HookEvent)
+}
+
+// WithHook adds a hook to the DB, can be used more than once
+func WithHook(hook Hook) OpenOption {
+	return func(o *openOptions) {
+		o.hooks = append(o.hooks, hook)
+	}
+}
+
+type operationKey struct{}
+
+type operationInfo struct {
+	op    Operation
+	model string
+}
+
+// withOperation sets the operation reported to the hooks for the statements executed with ctx,
+// unless it was already set by an outer operation
+func withOperation(ctx context.Context, op Operation, model string) context.Context {
+	if _, ok := ctx.Value(operationKey{}).(operationInfo); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, operationKey{}, operationInfo{op, model})
+}
+
+// replaceOperation is like withOperation, but it replaces the operation already in ctx
+func replaceOperation(ctx context.Context, op Operation, model string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operationInfo{op, model})
+}
+
+type hookCall struct {
+	// contexts[i] is the context returned by the Before of the i-th hook
+	contexts []context.Context
+	event    *HookEvent
+	start    time.Time
+}
+
+// beforeStatement calls the hooks for a statement, defaultOp is used when ctx doesn't have an operation
+func (q *DB) beforeStatement(ctx context.Context, defaultOp Operation, sql1 string) (context.Context, *hookCall) {
+	if len(q.hooks) == 0 {
+		return ctx, nil
+	}
+
+	info := operationOf(ctx, defaultOp)
+	return q.beforeHooks(ctx, &HookEvent{Operation: info.op, Model: info.model, SQL: sql1})
+}
+
+// operationOf returns the operation set in ctx by withOperation, defaultOp if there is none
+func operationOf(ctx context.Context, defaultOp Operation) operationInfo {
+	info, ok := ctx.Value(operationKey{}).(operationInfo)
+	if !ok {
+		info.op = defaultOp
+	}
+	return info
+}
+
+// beforeHooks calls the Before of all the hooks, the returned hookCall must be passed to afterHooks
+func (q *DB) beforeHooks(ctx context.Context, e *HookEvent) (context.Context, *hookCall) {
+	if len(q.hooks) == 0 {
+		return ctx, nil
+	}
+
+	contexts := make([]context.Context, len(q.hooks))
+	for i, h := range q.hooks {
+		ctx = h.Before(ctx, e)
+		contexts[i] = ctx
+	}
+	return ctx, &hookCall{contexts, e, time.Now()}
+}
+
+func (q *DB) afterHooks(c *hookCall, err error) {
+	if c == nil {
+		return
+	}
+	c.event.Duration = time.Since(c.start)
+	c.event.Err = err
+	for i := len(q.hooks) - 1; i >= 0; i-- {
+		q.hooks[i].After(c.contexts[i], c.event)
+	}
+}
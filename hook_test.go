@@ -0,0 +1,74 @@
+package sorm
+
+import (
+	"context"
+	"errors"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"testing"
+)
+
+type hookKey struct{}
+
+type recordingHook struct {
+	name   string
+	events []string
+}
+
+func (h *recordingHook) Before(ctx context.Context, e *HookEvent) context.Context {
+	return context.WithValue(ctx, hookKey{}, h.name)
+}
+
+func (h *recordingHook) After(ctx context.Context, e *HookEvent) {
+	if ctx.Value(hookKey{}) != h.name {
+		panic("After called with the wrong context")
+	}
+	event := string(e.Operation) + " " + e.Model
+	if e.Err != nil {
+		event += " error"
+	}
+	h.events = append(h.events, event)
+}
+
+func TestHooks(t *testing.T) {
+	AddModel(&queryTestModel{})
+
+	h1 := &recordingHook{name: "h1"}
+	h2 := &recordingHook{name: "h2"}
+	db, fake := newFakeDB(DriverMysql, WithHook(h1), WithHook(h2))
+
+	var dest []queryTestModel
+	err := Find(db, builder.MySQL(), &dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Count(db, builder.MySQL().From("[queryTestModel]"))
+	if !errors.Is(err, ErrEmptyResult) {
+		t.Fatalf("expected ErrEmptyResult, got %v", err)
+	}
+
+	err = db.Begin(func(q *TX) error {
+		return Insert(q, &queryTestModel{Name: "a"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.setExecError("DELETE", errors.New("boom"))
+	_ = Delete(db, &queryTestModel{ID: 1})
+
+	expected := []string{
+		"find queryTestModel",
+		"count ",
+		"begin ",
+		"insert queryTestModel",
+		"commit ",
+		"delete queryTestModel error",
+	}
+	if !reflect.DeepEqual(h1.events, expected) {
+		t.Errorf("expected %q, got %q", expected, h1.events)
+	}
+	if !reflect.DeepEqual(h2.events, expected) {
+		t.Errorf("expected %q, got %q", expected, h2.events)
+	}
+}
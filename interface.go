@@ -1,7 +1,9 @@
 package sorm
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -10,85 +12,185 @@ type Driver int
 const (
 	DriverMysql Driver = iota
 	DriverMssql
+	DriverPostgres
+	DriverSqlite
 )
 
 type DBTX interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	Driver() Driver
 
 	debugMode() bool
-}
-
-type dbStats struct {
-	queryCount int
-	timerStart time.Time
+	base() *DB
 }
 
 type DB struct {
 	db     *sql.DB
 	driver Driver
 	stats  *dbStats
+	health *healthChecker
+	logger QueryLogger
 	debug  bool
+
+	slowThreshold time.Duration
+	slowHandler   func(e *QueryEvent)
+
+	hooks     []Hook
+	stmtCache *stmtCache
+
+	replicas    *replicaSet
+	primaryOnly bool
+
+	clock func() time.Time
 }
 
 type TX struct {
-	tx         *sql.Tx
-	r          *DB
-	queryCount int
+	tx  *sql.Tx
+	r   *DB
+	ctx context.Context
+
+	savepoints int
+	onCommit   []func()
+	onRollback []func()
 }
 
 type TxFn func(q *TX) error
 
 func (q *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	result, err := q.db.Exec(query, args...)
+	return q.ExecContext(context.Background(), query, args...)
+}
+
+func (q *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.QueryContext(context.Background(), query, args...)
+}
+
+func (q *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	start := time.Now()
+	if stmt, release := q.stmtCache.prepared(ctx, q.db, query); stmt != nil {
+		result, err = stmt.ExecContext(ctx, args...)
+		release()
+	} else {
+		result, err = q.db.ExecContext(ctx, query, args...)
+	}
+	q.stats.addExec(operationOf(ctx, OpExec).op, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
-	q.stats.queryCount++
 	return result, nil
 }
 
-func (q *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	rows, err := q.db.Query(query, args...)
+func (q *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	start := time.Now()
+	db, cache := q.db, q.stmtCache
+	if r := q.replica(ctx); r != nil {
+		db, cache = r.db, r.stmtCache
+	}
+	if stmt, release := cache.prepared(ctx, db, query); stmt != nil {
+		rows, err = stmt.QueryContext(ctx, args...)
+		release()
+	} else {
+		rows, err = db.QueryContext(ctx, query, args...)
+	}
+	q.stats.addQuery(operationOf(ctx, OpQuery).op, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
-	q.stats.queryCount++
 	return rows, nil
 }
 
-func (q *DB) Begin(fn TxFn) (err error) {
-	tx, err := q.db.Begin()
+func (q *DB) Begin(fn TxFn) error {
+	return q.BeginContext(context.Background(), fn)
+}
+
+// BeginContext is like Begin, but the transaction is bound to ctx: if ctx is done
+// before the transaction is committed, it's rolled back.
+func (q *DB) BeginContext(ctx context.Context, fn TxFn) error {
+	return q.begin(ctx, nil, fn)
+}
+
+// BeginTx is like BeginContext, but the transaction is started with the specified options
+// (which can be nil), and fn is run again, in a new transaction, when it fails with an error
+// that the dialect considers retryable (e.g. a deadlock) as specified by opts.Retry.
+// So fn must not have side effects outside of the transaction.
+func (q *DB) BeginTx(ctx context.Context, opts *TxOptions, fn TxFn) error {
+	var txOpts *sql.TxOptions
+	var retry RetryPolicy
+	if opts != nil {
+		txOpts = &opts.TxOptions
+		retry = opts.Retry
+	}
+
+	dialect := q.driver.Dialect()
+	for attempt := 1; ; attempt++ {
+		err := q.begin(ctx, txOpts, fn)
+		if err == nil || attempt >= retry.MaxAttempts || !dialect.IsRetryable(err) {
+			return err
+		}
+		if err := retry.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+func (q *DB) begin(ctx context.Context, opts *sql.TxOptions, fn TxFn) (err error) {
+	hookCtx, hooks := q.beforeHooks(ctx, &HookEvent{Operation: OpBegin})
+	tx, err := q.db.BeginTx(hookCtx, opts)
+	q.afterHooks(hooks, err)
 	if err != nil {
 		return
 	}
+	t := &TX{tx: tx, r: q, ctx: ctx}
 	defer func() {
 		if p := recover(); p != nil {
-			_ = tx.Rollback()
+			_ = t.Rollback()
 			panic(p)
 		} else if err != nil {
-			_ = tx.Rollback()
+			_ = t.Rollback()
 		} else {
-			err = tx.Commit()
+			err = t.Commit()
 		}
 	}()
-	err = fn(&TX{tx: tx, r: q})
+	err = fn(t)
 	return err
 }
 
 // Debug clones the DB information object and sets it's debug mode to true.
-// The stats will be shared between the two objects
+// The stats will be shared between the two objects.
 func (q *DB) Debug() *DB {
 	if q.debug {
 		return q
 	}
-	return &DB{
-		db:     q.db,
-		stats:  q.stats,
-		driver: q.driver,
-		debug:  true,
+	clone := *q
+	clone.debug = true
+	return &clone
+}
+
+// Health returns the state of the periodic ping, which is always the zero value
+// if the DB was opened using WithoutPing.
+func (q *DB) Health() HealthStatus {
+	if q.health == nil {
+		return HealthStatus{}
+	}
+	return q.health.Status()
+}
+
+// Close stops the periodic ping, closes the cached statements, the replicas and the underlying sql.DB.
+// Since the clones returned by Debug share them, it closes the clones too.
+func (q *DB) Close() error {
+	if q.health != nil {
+		q.health.Stop()
+	}
+	if q.stmtCache != nil {
+		q.stmtCache.close()
 	}
+	replicasErr := q.replicas.close()
+	if err := q.db.Close(); err != nil {
+		return err
+	}
+	return replicasErr
 }
 
 func (q *DB) Driver() Driver {
@@ -99,35 +201,135 @@ func (q *DB) debugMode() bool {
 	return q.debug
 }
 
+func (q *DB) base() *DB {
+	return q
+}
+
 func (q *TX) Exec(query string, args ...interface{}) (sql.Result, error) {
-	result, err := q.tx.Exec(query, args...)
+	return q.ExecContext(context.Background(), query, args...)
+}
+
+func (q *TX) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.QueryContext(context.Background(), query, args...)
+}
+
+func (q *TX) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	start := time.Now()
+	if stmt, release := q.r.stmtCache.cached(query); stmt != nil {
+		result, err = q.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+		release()
+	} else {
+		result, err = q.tx.ExecContext(ctx, query, args...)
+	}
+	q.r.stats.addExec(operationOf(ctx, OpExec).op, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
-	q.queryCount++
 	return result, nil
 }
 
-func (q *TX) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	rows, err := q.tx.Query(query, args...)
+func (q *TX) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	start := time.Now()
+	if stmt, release := q.r.stmtCache.cached(query); stmt != nil {
+		rows, err = q.tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+		release()
+	} else {
+		rows, err = q.tx.QueryContext(ctx, query, args...)
+	}
+	q.r.stats.addQuery(operationOf(ctx, OpQuery).op, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
-	q.queryCount++
 	return rows, nil
 }
 
+// rollbackCallbacks is called when a savepoint is rolled back: the OnCommit callbacks
+// registered after it are discarded, while the OnRollback ones are run right away.
+func (q *TX) rollbackCallbacks(onCommit int, onRollback int) {
+	callbacks := q.onRollback[onRollback:]
+	q.onCommit = q.onCommit[:onCommit]
+	q.onRollback = q.onRollback[:onRollback]
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// OnCommit registers fn to be called after the transaction is committed successfully.
+// If it's registered inside a nested transaction that is rolled back, fn is discarded.
+func (q *TX) OnCommit(fn func()) {
+	q.onCommit = append(q.onCommit, fn)
+}
+
+// OnRollback registers fn to be called after the transaction is rolled back, or fails to
+// commit. If it's registered inside a nested transaction, fn is also called when the
+// nested transaction is rolled back.
+func (q *TX) OnRollback(fn func()) {
+	q.onRollback = append(q.onRollback, fn)
+}
+
 func (q *TX) Commit() error {
+	_, hooks := q.r.beforeHooks(q.ctx, &HookEvent{Operation: OpCommit})
 	err := q.tx.Commit()
+	q.r.afterHooks(hooks, err)
 	if err != nil {
+		q.runCallbacks(q.onRollback)
 		return err
 	}
-	q.r.stats.queryCount += q.queryCount
+	q.r.stats.addCommit()
+	q.runCallbacks(q.onCommit)
 	return nil
 }
 
 func (q *TX) Rollback() error {
-	return q.tx.Rollback()
+	_, hooks := q.r.beforeHooks(q.ctx, &HookEvent{Operation: OpRollback})
+	err := q.tx.Rollback()
+	q.r.afterHooks(hooks, err)
+	// even if the rollback failed the transaction can't be committed anymore
+	q.runCallbacks(q.onRollback)
+	if err != nil {
+		return err
+	}
+	q.r.stats.addRollback()
+	return nil
+}
+
+// runCallbacks runs the callbacks once the outcome of the transaction is known,
+// clearing both lists so that they can't run twice
+func (q *TX) runCallbacks(callbacks []func()) {
+	q.onCommit = nil
+	q.onRollback = nil
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// Begin runs fn in a nested transaction using a savepoint: if fn returns an error or panics
+// the changes made by fn are rolled back, while the outer transaction can still be committed.
+// This allows code that needs a transaction to be called both with a DB and a TX.
+func (q *TX) Begin(fn TxFn) (err error) {
+	q.savepoints++
+	name := fmt.Sprintf("sorm_sp%d", q.savepoints)
+	dialect := q.Driver().Dialect()
+
+	_, err = timedExec(q.ctx, q, dialect.Savepoint(name), nil, 0)
+	if err != nil {
+		return
+	}
+	onCommit, onRollback := len(q.onCommit), len(q.onRollback)
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = timedExec(q.ctx, q, dialect.RollbackSavepoint(name), nil, 1)
+			q.rollbackCallbacks(onCommit, onRollback)
+			panic(p)
+		} else if err != nil {
+			_, _ = timedExec(q.ctx, q, dialect.RollbackSavepoint(name), nil, 1)
+			q.rollbackCallbacks(onCommit, onRollback)
+		} else if release := dialect.ReleaseSavepoint(name); release != "" {
+			_, err = timedExec(q.ctx, q, release, nil, 1)
+		}
+	}()
+	err = fn(q)
+	return err
 }
 
 func (q *TX) Driver() Driver {
@@ -137,3 +339,7 @@ func (q *TX) Driver() Driver {
 func (q *TX) debugMode() bool {
 	return q.r.debug
 }
+
+func (q *TX) base() *DB {
+	return q.r
+}
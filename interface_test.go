@@ -0,0 +1,177 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNestedTransaction(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	err := db.Begin(func(q *TX) error {
+		err := q.Begin(func(q *TX) error {
+			return Insert(q, &queryTestModel{Name: "a"})
+		})
+		if err != nil {
+			return err
+		}
+		err = q.Begin(func(q *TX) error {
+			return errors.New("nested failure")
+		})
+		if err == nil {
+			t.Errorf("expected the nested error")
+		}
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			_ = q.Begin(func(q *TX) error {
+				panic("nested panic")
+			})
+		}()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"BEGIN",
+		"SAVEPOINT sorm_sp1",
+		"INSERT INTO `query_test` (`name`) Values (?)",
+		"RELEASE SAVEPOINT sorm_sp1",
+		"SAVEPOINT sorm_sp2",
+		"ROLLBACK TO SAVEPOINT sorm_sp2",
+		"SAVEPOINT sorm_sp3",
+		"ROLLBACK TO SAVEPOINT sorm_sp3",
+		"COMMIT",
+	}
+	if log := fake.log(); !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+}
+
+func TestNestedTransactionMssql(t *testing.T) {
+	db, fake := newFakeDB(DriverMssql)
+
+	err := db.Begin(func(q *TX) error {
+		return q.Begin(func(q *TX) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"BEGIN", "SAVE TRANSACTION sorm_sp1", "COMMIT"}
+	if log := fake.log(); !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+}
+
+func TestBeginTxRetry(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	fake.setExecError("INSERT", deadlock)
+
+	attempts := 0
+	opts := &TxOptions{
+		TxOptions: sql.TxOptions{ReadOnly: true},
+		Retry:     RetryPolicy{MaxAttempts: 3, Backoff: time.Microsecond},
+	}
+	err := db.BeginTx(context.Background(), opts, func(q *TX) error {
+		attempts++
+		if attempts == 3 {
+			fake.setExecError("INSERT", nil)
+		}
+		return Insert(q, &queryTestModel{Name: "a"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	stats := db.Stats()
+	if stats.Rollbacks != 2 || stats.Commits != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if log := fake.log(); log[0] != "BEGIN READ ONLY" {
+		t.Fatalf("expected a read only transaction, got %q", log[0])
+	}
+
+	// non retryable errors are returned immediately
+	attempts = 0
+	err = db.BeginTx(context.Background(), opts, func(q *TX) error {
+		attempts++
+		return errors.New("failure")
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected a single failed attempt, got %d (%v)", attempts, err)
+	}
+
+	// and retryable ones once the attempts are over
+	fake.setExecError("INSERT", deadlock)
+	attempts = 0
+	err = db.BeginTx(context.Background(), opts, func(q *TX) error {
+		attempts++
+		return Insert(q, &queryTestModel{Name: "a"})
+	})
+	if !errors.Is(err, deadlock) || attempts != 3 {
+		t.Fatalf("expected the deadlock after 3 attempts, got %d (%v)", attempts, err)
+	}
+}
+
+func TestTransactionCallbacks(t *testing.T) {
+	db, _ := newFakeDB(DriverMysql)
+
+	var calls []string
+	callback := func(name string) func() {
+		return func() {
+			calls = append(calls, name)
+		}
+	}
+
+	err := db.Begin(func(q *TX) error {
+		q.OnCommit(callback("commit 1"))
+		q.OnRollback(callback("rollback 1"))
+		_ = q.Begin(func(q *TX) error {
+			q.OnCommit(callback("nested commit"))
+			q.OnRollback(callback("nested rollback"))
+			return errors.New("nested failure")
+		})
+		_ = q.Begin(func(q *TX) error {
+			q.OnCommit(callback("commit 2"))
+			return nil
+		})
+		if len(calls) != 1 || calls[0] != "nested rollback" {
+			t.Errorf("expected only the nested rollback callback to be called, got %q", calls)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"nested rollback", "commit 1", "commit 2"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %q, got %q", expected, calls)
+	}
+
+	calls = nil
+	_ = db.Begin(func(q *TX) error {
+		q.OnCommit(callback("commit"))
+		q.OnRollback(callback("rollback"))
+		return errors.New("failure")
+	})
+	if !reflect.DeepEqual(calls, []string{"rollback"}) {
+		t.Errorf("expected only the rollback callback, got %q", calls)
+	}
+}
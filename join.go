@@ -0,0 +1,83 @@
+package sorm
+
+import (
+	"fmt"
+	"github.com/n1xx1/builder"
+	"strings"
+)
+
+// JoinRelation sets the From of b to the first model and joins the others (INNER JOIN) using the
+// dbfk relations between each of them and one of the models before it. The models are aliased
+// t0, t1, ... in order and the returned SelectedTable of every model can be passed to Query:
+//
+//	qs, err := sorm.Query(q, b, sorm.JoinRelation(b, "Order", "Customer")...)
+//	err = qs.Find(&orders, &customers)
+//
+// Like Preload, when there is more than one relation between two models the one to use must be
+// named with "as:" in the dbfk tag and the model written as "Model.Name" (like "Customer.Billing").
+func JoinRelation(b *builder.Builder, models ...string) []interface{} {
+	return joinRelation(b, "INNER", models)
+}
+
+// LeftJoinRelation is like JoinRelation with LEFT JOIN
+func LeftJoinRelation(b *builder.Builder, models ...string) []interface{} {
+	return joinRelation(b, "LEFT", models)
+}
+
+func joinRelation(b *builder.Builder, joinType string, modelNames []string) []interface{} {
+	models := make([]*ModelInfo, len(modelNames))
+	relations := make([]string, len(modelNames))
+	tables := make([]interface{}, len(modelNames))
+	for i, name := range modelNames {
+		if dot := strings.IndexByte(name, '.'); dot >= 0 {
+			name, relations[i] = name[:dot], name[dot+1:]
+		}
+		model, ok := modelNameCache[name]
+		if !ok {
+			panic(fmt.Errorf("unknown model %v", name))
+		}
+		models[i] = model
+		tables[i] = SelectTableAlias(name, fmt.Sprintf("t%d", i), nil)
+	}
+	if len(models) == 0 {
+		return tables
+	}
+
+	b.From("["+models[0].ModelName+"]", "t0")
+	for i := 1; i < len(models); i++ {
+		on, err := joinCondition(models, relations[i], i)
+		if err != nil {
+			panic(err)
+		}
+		b.Join(joinType, fmt.Sprintf("[%s] t%d", models[i].ModelName, i), on)
+	}
+	return tables
+}
+
+// joinCondition returns the ON of the join of models[i] with the first of the previous models
+// it has a relation with, in either direction. If relation is not empty only the relations with
+// that name are considered.
+func joinCondition(models []*ModelInfo, relation string, i int) (string, error) {
+	for j := 0; j < i; j++ {
+		var conds []string
+		for _, fi := range models[j].ForeignFields {
+			if fi.Model == models[i].ModelName && (relation == "" || fi.Name == relation) {
+				conds = append(conds, fmt.Sprintf("t%d.[%s.%s]=t%d.[%s.%s]",
+					j, models[j].ModelName, fi.Field.Name, i, models[i].ModelName, fi.JoinColumn()))
+			}
+		}
+		for _, fi := range models[i].ForeignFields {
+			if fi.Model == models[j].ModelName && (relation == "" || fi.Name == relation) {
+				conds = append(conds, fmt.Sprintf("t%d.[%s.%s]=t%d.[%s.%s]",
+					i, models[i].ModelName, fi.Field.Name, j, models[j].ModelName, fi.JoinColumn()))
+			}
+		}
+		if len(conds) > 1 {
+			return "", fmt.Errorf("more than one relation between model %s and model %s, use as: in dbfk", models[j].ModelName, models[i].ModelName)
+		}
+		if len(conds) == 1 {
+			return conds[0], nil
+		}
+	}
+	return "", fmt.Errorf("no relation between model %s and the models before it", models[i].ModelName)
+}
@@ -0,0 +1,71 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"github.com/n1xx1/builder"
+	"testing"
+)
+
+func TestJoinRelation(t *testing.T) {
+	AddModel(&preloadOrder{})
+	AddModel(&preloadCustomer{})
+	AddModel(&preloadItem{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("pl_order", []string{"q0", "q1", "q2", "q3", "q4", "q5"},
+		[]driver.Value{int64(1), int64(10), int64(10), "a", int64(100), int64(1)})
+
+	b := builder.Select()
+	qs, err := Query(db, b, JoinRelation(b, "preloadOrder", "preloadCustomer", "preloadItem")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orders []preloadOrder
+	var customers []preloadCustomer
+	var items []preloadItem
+	if err := qs.Find(&orders, &customers, &items); err != nil {
+		t.Fatal(err)
+	}
+	qs.Close()
+
+	expected := "SELECT t0.`id` as q0,t0.`customer_id` as q1,t1.`id` as q2,t1.`name` as q3,t2.`id` as q4,t2.`order_id` as q5" +
+		" FROM `pl_order` t0 INNER JOIN `pl_customer` t1 ON t0.`customer_id`=t1.`id` INNER JOIN `pl_item` t2 ON t0.`id`=t2.`order_id`"
+	if log := fake.log(); len(log) != 1 || log[0] != expected {
+		t.Fatalf("expected %q, got %q", expected, log)
+	}
+	if len(orders) != 1 || customers[0].Name != "a" || items[0].OrderID != 1 {
+		t.Errorf("unexpected results %v, %v and %v", orders, customers, items)
+	}
+}
+
+type joinInvoice struct {
+	ID         int `db:"id,primary"`
+	BillingID  int `db:"billing_id" dbfk:"preloadCustomer,as:Billing"`
+	ShippingID int `db:"shipping_id" dbfk:"preloadCustomer,as:Shipping"`
+}
+
+func (*joinInvoice) TableName() string {
+	return "join_invoice"
+}
+
+func TestJoinRelationAmbiguous(t *testing.T) {
+	AddModel(&joinInvoice{})
+	AddModel(&preloadCustomer{})
+
+	b := builder.Select()
+	JoinRelation(b, "joinInvoice", "preloadCustomer.Shipping")
+	sql1, _, err := b.ToSQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "SELECT * FROM [joinInvoice] t0 INNER JOIN [preloadCustomer] t1 ON t0.[joinInvoice.ShippingID]=t1.[preloadCustomer.ID]"
+	if sql1 != expected {
+		t.Errorf("expected %q, got %q", expected, sql1)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for the ambiguous relation")
+		}
+	}()
+	JoinRelation(builder.Select(), "joinInvoice", "preloadCustomer")
+}
@@ -0,0 +1,90 @@
+package sorm
+
+// BeforeInserter can be implemented by a model to be called by Insert and InsertMany before
+// the row is inserted, an error aborts the insert
+type BeforeInserter interface {
+	BeforeInsert(q DBTX) error
+}
+
+// AfterInserter can be implemented by a model to be called by Insert and InsertMany after
+// the row is inserted, with the autoincrement field set
+type AfterInserter interface {
+	AfterInsert(q DBTX) error
+}
+
+// BeforeUpdater can be implemented by a model to be called by Update, UpdateFields, UpdateAll,
+// UpdateTagged and Save before the row is updated, an error aborts the update
+type BeforeUpdater interface {
+	BeforeUpdate(q DBTX) error
+}
+
+// AfterUpdater can be implemented by a model to be called after the row is updated
+type AfterUpdater interface {
+	AfterUpdate(q DBTX) error
+}
+
+// BeforeDeleter can be implemented by a model to be called by Delete before the row is
+// deleted, an error aborts the delete
+type BeforeDeleter interface {
+	BeforeDelete(q DBTX) error
+}
+
+// AfterDeleter can be implemented by a model to be called by Delete after the row is deleted
+type AfterDeleter interface {
+	AfterDelete(q DBTX) error
+}
+
+// AfterScanner can be implemented by a struct to be called every time it's filled by a query,
+// with Find, Scan, Select or a QueryScanner
+type AfterScanner interface {
+	AfterScan(q DBTX) error
+}
+
+func beforeInsert(q DBTX, i interface{}) error {
+	if h, ok := i.(BeforeInserter); ok {
+		return h.BeforeInsert(q)
+	}
+	return nil
+}
+
+func afterInsert(q DBTX, i interface{}) error {
+	if h, ok := i.(AfterInserter); ok {
+		return h.AfterInsert(q)
+	}
+	return nil
+}
+
+func beforeUpdate(q DBTX, i interface{}) error {
+	if h, ok := i.(BeforeUpdater); ok {
+		return h.BeforeUpdate(q)
+	}
+	return nil
+}
+
+func afterUpdate(q DBTX, i interface{}) error {
+	if h, ok := i.(AfterUpdater); ok {
+		return h.AfterUpdate(q)
+	}
+	return nil
+}
+
+func beforeDelete(q DBTX, i interface{}) error {
+	if h, ok := i.(BeforeDeleter); ok {
+		return h.BeforeDelete(q)
+	}
+	return nil
+}
+
+func afterDelete(q DBTX, i interface{}) error {
+	if h, ok := i.(AfterDeleter); ok {
+		return h.AfterDelete(q)
+	}
+	return nil
+}
+
+func afterScan(q DBTX, i interface{}) error {
+	if h, ok := i.(AfterScanner); ok {
+		return h.AfterScan(q)
+	}
+	return nil
+}
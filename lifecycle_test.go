@@ -0,0 +1,122 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type lifecycleTestModel struct {
+	ID   int    `db:"id,primary,autoincrement"`
+	Name string `db:"name"`
+
+	calls []string
+}
+
+func (*lifecycleTestModel) TableName() string {
+	return "lifecycle_test"
+}
+
+func (m *lifecycleTestModel) BeforeInsert(q DBTX) error {
+	if m.Name == "" {
+		return errors.New("empty name")
+	}
+	m.Name = strings.TrimSpace(m.Name)
+	m.calls = append(m.calls, "BeforeInsert")
+	return nil
+}
+
+func (m *lifecycleTestModel) AfterInsert(q DBTX) error {
+	m.calls = append(m.calls, "AfterInsert")
+	return nil
+}
+
+func (m *lifecycleTestModel) BeforeUpdate(q DBTX) error {
+	m.calls = append(m.calls, "BeforeUpdate")
+	return nil
+}
+
+func (m *lifecycleTestModel) AfterUpdate(q DBTX) error {
+	m.calls = append(m.calls, "AfterUpdate")
+	return nil
+}
+
+func (m *lifecycleTestModel) BeforeDelete(q DBTX) error {
+	m.calls = append(m.calls, "BeforeDelete")
+	return nil
+}
+
+func (m *lifecycleTestModel) AfterScan(q DBTX) error {
+	m.calls = append(m.calls, "AfterScan")
+	return nil
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	AddModel(&lifecycleTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("SELECT", []string{"q0", "q1"}, []driver.Value{int64(1), "a"}, []driver.Value{int64(2), "b"})
+
+	m := &lifecycleTestModel{Name: " a "}
+	if err := Insert(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(db, m); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"BeforeInsert", "AfterInsert", "BeforeUpdate", "AfterUpdate", "BeforeDelete"}
+	if !reflect.DeepEqual(m.calls, expected) {
+		t.Errorf("expected %q, got %q", expected, m.calls)
+	}
+	if fake.args[0][0] != "a" {
+		t.Errorf("expected the name normalized by BeforeInsert, got %q", fake.args[0][0])
+	}
+
+	// an error from a Before hook aborts the operation
+	if err := Insert(db, &lifecycleTestModel{}); err == nil || len(fake.log()) != 3 {
+		t.Fatalf("expected the insert to be aborted, got %v", err)
+	}
+
+	var res []*lifecycleTestModel
+	if err := Find(db, builder.Select(), &res); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range res {
+		if !reflect.DeepEqual(r.calls, []string{"AfterScan"}) {
+			t.Errorf("expected AfterScan to be called, got %q", r.calls)
+		}
+	}
+}
+
+func TestLifecycleHooksUpsert(t *testing.T) {
+	AddModel(&lifecycleTestModel{})
+	db, _ := newFakeDB(DriverMysql)
+
+	// the insert hooks are called both with and without the id
+	for _, m := range []*lifecycleTestModel{{Name: "a"}, {ID: 5, Name: "a"}} {
+		if err := Upsert(db, m); err != nil {
+			t.Fatal(err)
+		}
+		if expected := []string{"BeforeInsert", "AfterInsert"}; !reflect.DeepEqual(m.calls, expected) {
+			t.Errorf("expected %v, got %v", expected, m.calls)
+		}
+	}
+}
+
+func TestLifecycleHooksRejectedUpdate(t *testing.T) {
+	AddModel(&lifecycleTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	m := &lifecycleTestModel{ID: 1, Name: "a"}
+	if err := UpdateFields(db, m, "Missing"); err == nil {
+		t.Fatal("expected an error for the unknown field")
+	}
+	if len(m.calls) != 0 || len(fake.log()) != 0 {
+		t.Errorf("expected no hook and no statement, got %v and %q", m.calls, fake.log())
+	}
+}
@@ -0,0 +1,75 @@
+package sorm
+
+import (
+	"time"
+)
+
+// QueryEvent describes a statement executed by sorm
+type QueryEvent struct {
+	// Time is when the statement completed
+	Time time.Time
+	// SQL is the statement sent to the database
+	SQL string
+	// InterpolatedSQL is SQL with the args inlined, meant only to be read
+	InterpolatedSQL string
+	Args            []interface{}
+	// Caller is the file:line of the code that called sorm
+	Caller   string
+	Duration time.Duration
+	// RowsAffected is -1 for queries or when it's not known
+	RowsAffected int64
+	Err          error
+	// Slow is true when the statement took longer than the slow query threshold
+	Slow bool
+}
+
+// QueryLogger receives the statements executed by a DB in debug mode
+type QueryLogger interface {
+	LogQuery(e *QueryEvent)
+}
+
+// QueryLoggerFunc is an adapter to use a function as a QueryLogger
+type QueryLoggerFunc func(e *QueryEvent)
+
+func (f QueryLoggerFunc) LogQuery(e *QueryEvent) {
+	f(e)
+}
+
+// WithQueryLogger sets the logger receiving the statements when the DB is in debug mode.
+// By default they are printed with colors on stderr.
+func WithQueryLogger(logger QueryLogger) OpenOption {
+	return func(o *openOptions) {
+		o.logger = logger
+	}
+}
+
+type stderrQueryLogger struct{}
+
+func (stderrQueryLogger) LogQuery(e *QueryEvent) {
+	dbl.Println(logFormatter(e)...)
+}
+
+// WithSlowQueryThreshold enables the slow query detection: the statements lasting at least
+// threshold are sent to the slow query handler, or to the query logger if there isn't one,
+// even when the DB is not in debug mode.
+func WithSlowQueryThreshold(threshold time.Duration) OpenOption {
+	return func(o *openOptions) {
+		o.slowThreshold = threshold
+	}
+}
+
+// WithSlowQueryHandler sets the function called for the statements exceeding the slow
+// query threshold set with WithSlowQueryThreshold.
+func WithSlowQueryHandler(handler func(e *QueryEvent)) OpenOption {
+	return func(o *openOptions) {
+		o.slowHandler = handler
+	}
+}
+
+func (q *DB) logQuery(e *QueryEvent) {
+	logger := q.logger
+	if logger == nil {
+		logger = stderrQueryLogger{}
+	}
+	logger.LogQuery(e)
+}
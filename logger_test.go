@@ -0,0 +1,87 @@
+package sorm
+
+import (
+	"errors"
+	"github.com/n1xx1/builder"
+	"strings"
+	"testing"
+)
+
+func TestQueryLogger(t *testing.T) {
+	AddModel(&queryTestModel{})
+
+	var events []*QueryEvent
+	db, fake := newFakeDB(DriverMysql, WithQueryLogger(QueryLoggerFunc(func(e *QueryEvent) {
+		events = append(events, e)
+	})))
+
+	// not in debug mode, nothing is logged
+	err := Exec(db, builder.MySQL().From("[queryTestModel]").Delete(builder.Eq{"[!queryTestModel.ID]": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	err = Exec(db.Debug(), builder.MySQL().From("[queryTestModel]").Delete(builder.Eq{"[!queryTestModel.Name]": "a'b"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.SQL != "DELETE FROM `query_test` WHERE `query_test`.`name`=?" {
+		t.Errorf("unexpected sql %q", e.SQL)
+	}
+	if e.InterpolatedSQL != "DELETE FROM `query_test` WHERE `query_test`.`name`='a\\'b'" {
+		t.Errorf("unexpected interpolated sql %q", e.InterpolatedSQL)
+	}
+	if e.RowsAffected != 1 || e.Err != nil {
+		t.Errorf("unexpected result %d %v", e.RowsAffected, e.Err)
+	}
+	if !strings.Contains(e.Caller, "logger_test.go:") {
+		t.Errorf("unexpected caller %q", e.Caller)
+	}
+
+	fake.setExecError("DELETE", errors.New("boom"))
+	_ = Exec(db.Debug(), builder.MySQL().From("[queryTestModel]").Delete(builder.Eq{"[!queryTestModel.ID]": 1}))
+	if len(events) != 2 || events[1].Err == nil || events[1].RowsAffected != -1 {
+		t.Fatalf("expected a failed event, got %+v", events[len(events)-1])
+	}
+}
+
+func TestSlowQuery(t *testing.T) {
+	AddModel(&queryTestModel{})
+
+	var logged, slow []*QueryEvent
+	db, _ := newFakeDB(DriverMysql, WithSlowQueryThreshold(1), WithQueryLogger(QueryLoggerFunc(func(e *QueryEvent) {
+		logged = append(logged, e)
+	})), WithSlowQueryHandler(func(e *QueryEvent) {
+		slow = append(slow, e)
+	}))
+
+	err := Exec(db, builder.MySQL().From("[queryTestModel]").Delete(builder.Eq{"[!queryTestModel.ID]": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slow) != 1 || len(logged) != 0 {
+		t.Fatalf("expected only the slow handler to be called, got %d slow and %d logged", len(slow), len(logged))
+	}
+	if !slow[0].Slow || !strings.Contains(slow[0].Caller, "logger_test.go:") {
+		t.Errorf("unexpected event %+v", slow[0])
+	}
+
+	// without a handler the slow queries go to the logger
+	db, _ = newFakeDB(DriverMysql, WithSlowQueryThreshold(1), WithQueryLogger(QueryLoggerFunc(func(e *QueryEvent) {
+		logged = append(logged, e)
+	})))
+	err = Exec(db, builder.MySQL().From("[queryTestModel]").Delete(builder.Eq{"[!queryTestModel.ID]": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || !logged[0].Slow {
+		t.Fatalf("expected a slow event to be logged, got %v", logged)
+	}
+}
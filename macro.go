@@ -56,33 +56,29 @@ func macroFuncMin(args []string, driver Driver) (string, error) {
 	if len(args) < 2 {
 		return "", fmt.Errorf("wrong argument count for MIN! (expected 2 or more, got %d instead)", len(args))
 	}
-	if driver == DriverMssql {
-		values := "(" + strings.Join(args, "),(") + ")"
-		return fmt.Sprintf("(SELECT MIN(i) FROM (VALUES %s) AS T(i))", values), nil
-	}
-	values := strings.Join(args, ",")
-	return fmt.Sprintf("LEAST(%s)", values), nil
+	return dialectMacro("MIN", args, driver)
 }
 func macroFuncMax(args []string, driver Driver) (string, error) {
 	if len(args) < 2 {
 		return "", fmt.Errorf("wrong argument count for MAX! (expected 2 or more, got %d instead)", len(args))
 	}
-	if driver == DriverMssql {
-		values := "(" + strings.Join(args, "),(") + ")"
-		return fmt.Sprintf("(SELECT MAX(i) FROM (VALUES %s) AS T(i))", values), nil
-	}
-	values := "SELECT " + args[0] + " AS i UNION SELECT " + strings.Join(args[1:], " UNION SELECT ")
-	return fmt.Sprintf("(SELECT MAX(v.i) FROM (%s) v)", values), nil
+	return dialectMacro("MAX", args, driver)
 }
 func macroFuncAddMonths(args []string, driver Driver) (string, error) {
 	if len(args) != 2 {
 		return "", fmt.Errorf("wrong argument count for ADDMONTH! (expected 2, got %d instead)", len(args))
 	}
-	if driver == DriverMssql {
-		return fmt.Sprintf("DATEADD(month, %s, %s)", args[1], args[0]), nil
-	} else {
-		return fmt.Sprintf("DATE_ADD(%s, INTERVAL %s MONTH)", args[0], args[1]), nil
+	return dialectMacro("ADDMONTH", args, driver)
+}
+
+// dialectMacro renders a macro which syntax depends on the database
+func dialectMacro(name string, args []string, driver Driver) (string, error) {
+	dialect := driver.Dialect()
+	repl, ok := dialect.Macro(name, args)
+	if !ok {
+		return "", fmt.Errorf("macro %s! is not supported by %s", name, dialect.Name())
 	}
+	return repl, nil
 }
 
 type MacroFunc func(args []string, driver Driver) (string, error)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 func tagContain(s []string, e string) bool {
@@ -27,6 +28,10 @@ type ModelInfo struct {
 	PrimaryFields []*FieldInfo
 	Fields        []*FieldInfo
 	ForeignFields []*ForeignInfo
+	// VersionField is the field used for optimistic locking by the updates, nil if there isn't one
+	VersionField *FieldInfo
+	// SoftDeleteField is the deletion time set by Delete instead of removing the row, nil if there isn't one
+	SoftDeleteField *FieldInfo
 
 	fieldNameMap   map[string]*FieldInfo
 	fieldDbNameMap map[string]*FieldInfo
@@ -42,6 +47,8 @@ func (m *ModelInfo) FieldByDbName(dbName string) *FieldInfo {
 // FieldsWithTag returns the list of fields that have the specified tag.
 // if one of the tags is "primary" then all the primary fields are also returned
 // if one of the tags is "autoincrement" then the autoincrement field is returned
+// if one of the tags is "version" then the version field is returned
+// if one of the tags is "softdelete" then the softdelete field is returned
 func (m *ModelInfo) FieldsWithTag(tag ...string) []*FieldInfo {
 	var ret []*FieldInfo
 
@@ -55,6 +62,14 @@ func (m *ModelInfo) FieldsWithTag(tag ...string) []*FieldInfo {
 				ret = append(ret, f)
 				break
 			}
+			if t == "version" && f.IsVersion {
+				ret = append(ret, f)
+				break
+			}
+			if t == "softdelete" && f.IsSoftDelete {
+				ret = append(ret, f)
+				break
+			}
 			if tagContain(f.Tags, t) {
 				ret = append(ret, f)
 				break
@@ -84,12 +99,20 @@ type FieldInfo struct {
 	IsPrimary       bool
 	IsAutoIncrement bool
 	IsForeign       bool
+	IsVersion       bool
+	IsSoftDelete    bool
+	IsAutoCreate    bool
+	IsAutoUpdate    bool
 }
 
 type ForeignInfo struct {
-	Name       string
-	Field      *FieldInfo
-	Model      string
+	// Name is the field loaded by Preload with the related models (set with "as:" in the
+	// dbfk tag), if empty it's the only field having the type of the related model
+	Name  string
+	Field *FieldInfo
+	Model string
+	// JoinTable is the table of the related rows of a OneToMany relation (set with "table:"
+	// in the dbfk tag)
 	JoinTable  string
 	joinColumn string
 	Kind       ForeignKind
@@ -183,6 +206,36 @@ func computeFieldCacheRec(model *ModelInfo, typ reflect.Type, path []int) {
 			case tag == "primary":
 				field.IsPrimary = true
 				model.PrimaryFields = append(model.PrimaryFields, field)
+			case tag == "version":
+				switch f.Type.Kind() {
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				default:
+					panic(fmt.Sprintf("version field %s in model %s must be an integer", name, model.ModelName))
+				}
+				if model.VersionField != nil {
+					panic(fmt.Sprintf("model %s has more than one version field", model.ModelName))
+				}
+				field.IsVersion = true
+				model.VersionField = field
+			case tag == "softdelete":
+				if f.Type != reflect.TypeOf((*time.Time)(nil)) {
+					panic(fmt.Sprintf("softdelete field %s in model %s must be a *time.Time", name, model.ModelName))
+				}
+				if model.SoftDeleteField != nil {
+					panic(fmt.Sprintf("model %s has more than one softdelete field", model.ModelName))
+				}
+				field.IsSoftDelete = true
+				model.SoftDeleteField = field
+			case tag == "autocreatetime" || tag == "autoupdatetime":
+				if f.Type != reflect.TypeOf(time.Time{}) && f.Type != reflect.TypeOf((*time.Time)(nil)) {
+					panic(fmt.Sprintf("%s field %s in model %s must be a time.Time or *time.Time", tag, name, model.ModelName))
+				}
+				if tag == "autocreatetime" {
+					field.IsAutoCreate = true
+				} else {
+					field.IsAutoUpdate = true
+				}
 			default:
 				panic(fmt.Sprintf("invalid attribute specified in tag 'db' for field %s in model %s", name, model.ModelName))
 			}
@@ -203,6 +256,8 @@ func computeFieldCacheRec(model *ModelInfo, typ reflect.Type, path []int) {
 					foreign.JoinTable = tag1
 				} else if tag1 := strings.TrimPrefix(tag, "col:"); len(tag1) != len(tag) {
 					foreign.joinColumn = tag1
+				} else if tag1 := strings.TrimPrefix(tag, "as:"); len(tag1) != len(tag) {
+					foreign.Name = tag1
 				}
 			}
 
@@ -0,0 +1,165 @@
+package sorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"github.com/n1xx1/builder"
+	"reflect"
+)
+
+// FindOption changes how Find loads the rows
+type FindOption func(o *findOptions)
+
+type findOptions struct {
+	preload []string
+}
+
+// Preload makes Find load the models related to the found ones through a dbfk field, with a
+// single query for all of them, and put them in the specified field, which must be excluded
+// from the columns with db:"-". For a OneToOne relation the field is a model or a pointer to a
+// model and it's set to the row whose JoinColumn is equal to the dbfk field, for a OneToMany
+// relation it's a slice of models (or of pointers) and it's filled with all the matching rows.
+func Preload(field string) FindOption {
+	return func(o *findOptions) {
+		o.preload = append(o.preload, field)
+	}
+}
+
+// preloadTarget returns the related model of the field and the foreign info of the relation
+func preloadTarget(model *ModelInfo, field reflect.StructField) (*ModelInfo, *ForeignInfo, error) {
+	typ := field.Type
+	isSlice := typ.Kind() == reflect.Slice
+	if isSlice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	related := modelCache[typ]
+	if related == nil {
+		return nil, nil, fmt.Errorf("preload field %s of model %s is not a model", field.Name, model.ModelName)
+	}
+
+	var foreign *ForeignInfo
+	for _, fi := range model.ForeignFields {
+		if fi.Name == field.Name {
+			foreign = fi
+			break
+		}
+		if fi.Name == "" && fi.Model == related.ModelName {
+			if foreign != nil {
+				return nil, nil, fmt.Errorf("more than one relation with model %s in model %s, use as: in dbfk", related.ModelName, model.ModelName)
+			}
+			foreign = fi
+		}
+	}
+	if foreign == nil || foreign.Model != related.ModelName {
+		return nil, nil, fmt.Errorf("no relation for preload field %s of model %s", field.Name, model.ModelName)
+	}
+	if isSlice != (foreign.Kind == OneToMany) {
+		return nil, nil, fmt.Errorf("preload field %s of model %s doesn't match the kind of relation", field.Name, model.ModelName)
+	}
+	if foreign.JoinTable != "" && foreign.JoinTable != related.TableName && foreign.JoinTable != related.ModelName {
+		// the rows would be related through another table, which is not supported
+		return nil, nil, fmt.Errorf("preload field %s of model %s: join table %s is not supported", field.Name, model.ModelName, foreign.JoinTable)
+	}
+	return related, foreign, nil
+}
+
+// preloadKey returns the value of a key field used to match the rows, nil if it's a nil pointer.
+// It's the value passed to the database driver, since a key can be an int in a model and an int64
+// in the other one.
+func preloadKey(v reflect.Value) interface{} {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	key, err := driver.DefaultParameterConverter.ConvertValue(convertToDbType(v))
+	if err != nil {
+		return v.Interface()
+	}
+	if b, ok := key.([]byte); ok {
+		// slices can't be map keys
+		return string(b)
+	}
+	return key
+}
+
+func doPreload(ctx context.Context, calldepth int, q DBTX, model *ModelInfo, parents []reflect.Value, fieldName string) error {
+	field, ok := model.Type.FieldByName(fieldName)
+	if !ok {
+		return fmt.Errorf("field %s not found in model %s", fieldName, model.ModelName)
+	}
+	related, foreign, err := preloadTarget(model, field)
+	if err != nil {
+		return err
+	}
+	ctx = replaceOperation(ctx, OpFind, related.ModelName)
+	joinField := related.FieldByName(foreign.JoinColumn())
+	if joinField == nil {
+		return fmt.Errorf("field %s not found in model %s", foreign.JoinColumn(), related.ModelName)
+	}
+
+	var keys []interface{}
+	seen := map[interface{}]bool{}
+	for _, p := range parents {
+		val := p.FieldByIndex(foreign.Field.StructFieldPath)
+		key := preloadKey(val)
+		if key == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, convertToDbType(reflect.Indirect(val)))
+	}
+
+	// every related row, grouped by the value of the join column
+	children := map[interface{}][]reflect.Value{}
+	maxParams, _ := q.Driver().Dialect().BatchLimits()
+	for len(keys) > 0 {
+		n := len(keys)
+		if maxParams > 0 && n > maxParams {
+			n = maxParams
+		}
+
+		rows := reflect.New(reflect.SliceOf(reflect.PtrTo(related.Type)))
+		column := fmt.Sprintf("[!%s.%s]", related.ModelName, joinField.Name)
+		b := q.Driver().Dialect().Builder().Where(builder.In(column, keys[:n]...))
+		err := doFindTx(ctx, calldepth+1, q, b, rows.Interface())
+		if err != nil {
+			return err
+		}
+		for i := 0; i < rows.Elem().Len(); i++ {
+			row := rows.Elem().Index(i)
+			key := preloadKey(row.Elem().FieldByIndex(joinField.StructFieldPath))
+			children[key] = append(children[key], row)
+		}
+		keys = keys[n:]
+	}
+
+	for _, p := range parents {
+		dest := p.FieldByIndex(field.Index)
+		matches := children[preloadKey(p.FieldByIndex(foreign.Field.StructFieldPath))]
+
+		if foreign.Kind == OneToMany {
+			slice := reflect.MakeSlice(dest.Type(), 0, len(matches))
+			for _, m := range matches {
+				if dest.Type().Elem().Kind() != reflect.Ptr {
+					m = m.Elem()
+				}
+				slice = reflect.Append(slice, m)
+			}
+			dest.Set(slice)
+			continue
+		}
+
+		if len(matches) == 0 {
+			dest.Set(reflect.Zero(dest.Type()))
+		} else if dest.Kind() == reflect.Ptr {
+			dest.Set(matches[0])
+		} else {
+			dest.Set(matches[0].Elem())
+		}
+	}
+	return nil
+}
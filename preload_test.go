@@ -0,0 +1,130 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"testing"
+)
+
+type preloadOrder struct {
+	ID         int `db:"id,primary" dbfk:"preloadItem,table:pl_item,col:OrderID"`
+	CustomerID int `db:"customer_id" dbfk:"preloadCustomer"`
+
+	Customer *preloadCustomer `db:"-"`
+	Items    []preloadItem    `db:"-"`
+}
+
+func (*preloadOrder) TableName() string {
+	return "pl_order"
+}
+
+type preloadCustomer struct {
+	ID   int    `db:"id,primary"`
+	Name string `db:"name"`
+}
+
+func (*preloadCustomer) TableName() string {
+	return "pl_customer"
+}
+
+type preloadItem struct {
+	ID      int `db:"id,primary"`
+	OrderID int `db:"order_id"`
+}
+
+func (*preloadItem) TableName() string {
+	return "pl_item"
+}
+
+func TestPreload(t *testing.T) {
+	AddModel(&preloadOrder{})
+	AddModel(&preloadCustomer{})
+	AddModel(&preloadItem{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("pl_order", []string{"q0", "q1"},
+		[]driver.Value{int64(1), int64(10)}, []driver.Value{int64(2), int64(10)}, []driver.Value{int64(3), int64(11)})
+	fake.setRows("pl_customer", []string{"q0", "q1"}, []driver.Value{int64(10), "a"})
+	fake.setRows("pl_item", []string{"q0", "q1"},
+		[]driver.Value{int64(100), int64(1)}, []driver.Value{int64(101), int64(1)}, []driver.Value{int64(102), int64(2)})
+
+	var orders []preloadOrder
+	err := Find(db, builder.Select(), &orders, Preload("Customer"), Preload("Items"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"SELECT `pl_order`.`id` as q0,`pl_order`.`customer_id` as q1 FROM `pl_order`",
+		"SELECT `pl_customer`.`id` as q0,`pl_customer`.`name` as q1 FROM `pl_customer` WHERE `pl_customer`.`id` IN (?,?)",
+		"SELECT `pl_item`.`id` as q0,`pl_item`.`order_id` as q1 FROM `pl_item` WHERE `pl_item`.`order_id` IN (?,?,?)",
+	}
+	log := fake.log()
+	for i := range expected {
+		if i >= len(log) || log[i] != expected[i] {
+			t.Fatalf("expected %q, got %q", expected, log)
+		}
+	}
+
+	if orders[0].Customer == nil || orders[0].Customer.Name != "a" || orders[1].Customer != orders[0].Customer {
+		t.Errorf("expected the customer of the first two orders")
+	}
+	if orders[2].Customer != nil {
+		t.Errorf("expected no customer for the third order")
+	}
+	if len(orders[0].Items) != 2 || len(orders[1].Items) != 1 || len(orders[2].Items) != 0 {
+		t.Errorf("unexpected items %v, %v and %v", orders[0].Items, orders[1].Items, orders[2].Items)
+	}
+
+	if err := Find(db, builder.Select(), &orders, Preload("Missing")); err == nil {
+		t.Errorf("expected an error for the unknown field")
+	}
+}
+
+func TestPreloadHooks(t *testing.T) {
+	AddModel(&preloadOrder{})
+	AddModel(&preloadCustomer{})
+	h := &recordingHook{name: "h"}
+	db, fake := newFakeDB(DriverMysql, WithHook(h))
+	fake.setRows("pl_order", []string{"q0", "q1"}, []driver.Value{int64(1), int64(10)})
+
+	var orders []preloadOrder
+	if err := Find(db, builder.Select(), &orders, Preload("Customer")); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"find preloadOrder", "find preloadCustomer"}
+	if !reflect.DeepEqual(h.events, expected) {
+		t.Errorf("expected %v, got %v", expected, h.events)
+	}
+}
+
+func TestPreloadKey(t *testing.T) {
+	if preloadKey(reflect.ValueOf(1)) != preloadKey(reflect.ValueOf(int64(1))) {
+		t.Errorf("expected the same key for int and int64")
+	}
+	if preloadKey(reflect.ValueOf(int64(1))) == preloadKey(reflect.ValueOf("1")) {
+		t.Errorf("expected different keys for int64 and string")
+	}
+}
+
+type preloadPivotOrder struct {
+	ID int `db:"id,primary" dbfk:"preloadItem,table:pl_order_item,col:OrderID"`
+
+	Items []preloadItem `db:"-"`
+}
+
+func (*preloadPivotOrder) TableName() string {
+	return "pl_pivot_order"
+}
+
+func TestPreloadJoinTable(t *testing.T) {
+	AddModel(&preloadPivotOrder{})
+	AddModel(&preloadItem{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("pl_pivot_order", []string{"q0"}, []driver.Value{int64(1)})
+
+	var orders []preloadPivotOrder
+	if err := Find(db, builder.Select(), &orders, Preload("Items")); err == nil {
+		t.Fatal("expected an error for the join table")
+	}
+}
@@ -3,14 +3,10 @@ package sorm
 import (
 	"fmt"
 	"regexp"
-	"strconv"
 )
 
 func SqlEscape(driver Driver, table string) string {
-	if driver == DriverMssql {
-		return "[" + table + "]"
-	}
-	return "`" + table + "`"
+	return driver.Dialect().Quote(table)
 }
 
 var regexField = regexp.MustCompile(`\[(!?)([a-zA-Z_][a-zA-Z0-9_]*)(?:\.([a-zA-Z_][a-zA-Z0-9_]*))?]`)
@@ -18,16 +14,15 @@ var regexParam = regexp.MustCompile(`\?`)
 var regexParamMs = regexp.MustCompile(`@p(\d+)`)
 
 func ConvertQuery(driver Driver, query string, args []interface{}) (string, []interface{}) {
-	if driver == DriverMssql {
-		return query, args
+	return driver.Dialect().ConvertQuery(query, args)
+}
+
+// formatQuery is FormatQuery followed by ConvertQuery, using the statement cache of the DB when enabled
+func formatQuery(q DBTX, query string, args []interface{}) (string, []interface{}) {
+	if cache := q.base().stmtCache; cache != nil {
+		return cache.format(q.Driver(), query, args)
 	}
-	realArgs := make([]interface{}, 0, len(args))
-	query = ReplaceAllStringSubmatchFunc(regexParamMs, query, func(groups []string) string {
-		pos, _ := strconv.ParseInt(groups[1], 10, 32)
-		realArgs = append(realArgs, args[pos-1])
-		return "?"
-	})
-	return query, realArgs
+	return ConvertQuery(q.Driver(), FormatQuery(q.Driver(), query), args)
 }
 
 func FormatQuery(driver Driver, query string) string {
@@ -0,0 +1,128 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type queryTestModel struct {
+	ID   int    `db:"id,primary,autoincrement"`
+	Name string `db:"name"`
+}
+
+func (*queryTestModel) TableName() string {
+	return "query_test"
+}
+
+func TestFormatQuery(t *testing.T) {
+	AddModel(&queryTestModel{})
+
+	tests := []struct {
+		driver   Driver
+		input    string
+		args     []interface{}
+		expected string
+		realArgs []interface{}
+	}{
+		{DriverMysql, "SELECT [!queryTestModel.Name] FROM [queryTestModel] WHERE [!queryTestModel.ID]=? AND [queryTestModel.Name]=?", []interface{}{1, "a"},
+			"SELECT `query_test`.`name` FROM `query_test` WHERE `query_test`.`id`=? AND `name`=?", []interface{}{1, "a"}},
+		{DriverMssql, "SELECT [!queryTestModel.Name] FROM [queryTestModel] WHERE [!queryTestModel.ID]=? AND [queryTestModel.Name]=?", []interface{}{1, "a"},
+			"SELECT [query_test].[name] FROM [query_test] WHERE [query_test].[id]=@p1 AND [name]=@p2", []interface{}{1, "a"}},
+		{DriverPostgres, "SELECT [!queryTestModel.Name] FROM [queryTestModel] WHERE [!queryTestModel.ID]=? AND [queryTestModel.Name]=?", []interface{}{1, "a"},
+			`SELECT "query_test"."name" FROM "query_test" WHERE "query_test"."id"=$1 AND "name"=$2`, []interface{}{1, "a"}},
+		{DriverPostgres, "SELECT MAX!(a, b), MIN!(a, b, c), ADDMONTH!(d, ?)", []interface{}{2},
+			"SELECT GREATEST(a,b), LEAST(a,b,c), (d + ($1) * INTERVAL '1 month')", []interface{}{2}},
+		{DriverSqlite, "SELECT [!queryTestModel.Name] FROM [queryTestModel] WHERE [!queryTestModel.ID]=? AND [queryTestModel.Name]=?", []interface{}{1, "a"},
+			`SELECT "query_test"."name" FROM "query_test" WHERE "query_test"."id"=? AND "name"=?`, []interface{}{1, "a"}},
+		{DriverSqlite, "SELECT MAX!(a, b), MIN!(a, b, c), ADDMONTH!(d, 3), ADDMONTH!(d, -1), ADDMONTH!(d, ?)", []interface{}{2},
+			"SELECT max(a,b), min(a,b,c), date(d, '+3 months'), date(d, '-1 months'), date(d, (?) || ' months')", []interface{}{2}},
+	}
+
+	for _, test := range tests {
+		query := FormatQuery(test.driver, test.input)
+		query, args := ConvertQuery(test.driver, query, test.args)
+		if query != test.expected {
+			t.Errorf("driver %v: expected %q, got %q", test.driver, test.expected, query)
+		}
+		if !reflect.DeepEqual(args, test.realArgs) {
+			t.Errorf("driver %v: expected args %v, got %v", test.driver, test.realArgs, args)
+		}
+	}
+}
+
+type testDialect struct {
+	sqliteDialect
+}
+
+func (testDialect) Name() string {
+	return "test"
+}
+
+func (testDialect) Quote(identifier string) string {
+	return "<" + identifier + ">"
+}
+
+func (testDialect) Macro(name string, args []string) (string, bool) {
+	return "", false
+}
+
+func TestRegisterDialect(t *testing.T) {
+	AddModel(&queryTestModel{})
+	driver := RegisterDialect(testDialect{})
+
+	query := FormatQuery(driver, "SELECT [!queryTestModel.Name] FROM [queryTestModel]")
+	if expected := "SELECT <query_test>.<name> FROM <query_test>"; query != expected {
+		t.Errorf("expected %q, got %q", expected, query)
+	}
+
+	_, err := PerformQueryMacro("SELECT MAX!(a, b)", driver)
+	if err == nil {
+		t.Errorf("expected an error for an unsupported macro")
+	}
+}
+
+func TestWritePostgres(t *testing.T) {
+	testWrite(t, DriverPostgres, []string{
+		`INSERT INTO "query_test" ("name") Values ($1) RETURNING "id"`,
+		`UPDATE "query_test" SET "name"=$1 WHERE "query_test"."id"=$2`,
+		`UPDATE "soft_delete_test" SET "deleted_at"=$1 WHERE "soft_delete_test"."id"=$2 AND "soft_delete_test"."deleted_at" IS NULL`,
+		`DELETE FROM "soft_delete_test" WHERE "soft_delete_test"."id"=$1`,
+	})
+}
+
+func TestWriteSqlite(t *testing.T) {
+	testWrite(t, DriverSqlite, []string{
+		`INSERT INTO "query_test" ("name") Values (?)`,
+		`UPDATE "query_test" SET "name"=? WHERE "query_test"."id"=?`,
+		`UPDATE "soft_delete_test" SET "deleted_at"=? WHERE "soft_delete_test"."id"=? AND "soft_delete_test"."deleted_at" IS NULL`,
+		`DELETE FROM "soft_delete_test" WHERE "soft_delete_test"."id"=?`,
+	})
+}
+
+// testWrite checks the statements of Insert, Update, Delete and Unscoped Delete, where the
+// columns of INSERT and the targets of SET are never qualified with the table name
+func testWrite(t *testing.T, d Driver, expected []string) {
+	AddModel(&queryTestModel{})
+	AddModel(&softDeleteTestModel{})
+	db, fake := newFakeDB(d)
+	fake.setRows("RETURNING", []string{"id"}, []driver.Value{int64(1)})
+
+	m := &queryTestModel{Name: "a"}
+	if err := Insert(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(db, &softDeleteTestModel{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(Unscoped(db), &softDeleteTestModel{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if log := fake.log(); !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+}
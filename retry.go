@@ -0,0 +1,58 @@
+package sorm
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how a transaction is retried when it fails because of a deadlock,
+// a serialization failure or a lock timeout (see Dialect.IsRetryable).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 0 or 1 means no retries
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled on every following one
+	Backoff time.Duration
+	// MaxBackoff caps the wait between two attempts, no cap if zero
+	MaxBackoff time.Duration
+}
+
+// TxOptions are the options used by DB.BeginTx
+type TxOptions struct {
+	sql.TxOptions
+	Retry RetryPolicy
+}
+
+// delay returns how much to wait after the specified failed attempt (starting from 1), with
+// a random jitter so that the transactions involved in a deadlock don't retry at the same time
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff != 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// wait sleeps before the next attempt, returning early with the context error if ctx is done
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	d := p.delay(attempt)
+	if d == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package sorm
+
+import (
+	"fmt"
+	"github.com/n1xx1/builder"
+	"regexp"
+)
+
+type unscopedDBTX struct {
+	DBTX
+}
+
+// Unscoped returns q ignoring the softdelete fields: the queries return the soft deleted rows
+// too and Delete removes the rows
+func Unscoped(q DBTX) DBTX {
+	if isUnscoped(q) {
+		return q
+	}
+	return unscopedDBTX{q}
+}
+
+func isUnscoped(q DBTX) bool {
+	_, ok := q.(unscopedDBTX)
+	return ok
+}
+
+var regexFromModel = regexp.MustCompile(`^\[(\w+)\](?:\s+(\w+))?$`)
+
+// scopeSoftDelete excludes the soft deleted rows from a query when its From is a model (as
+// [Model], optionally with an alias) with a softdelete field. b is not changed, the condition
+// is added to a copy, so that b can be reused (like the counter and the selector of PagedQuery).
+func scopeSoftDelete(q DBTX, b *builder.Builder) *builder.Builder {
+	if isUnscoped(q) {
+		return b
+	}
+	groups := regexFromModel.FindStringSubmatch(b.TableName())
+	if groups == nil {
+		return b
+	}
+	model, ok := modelNameCache[groups[1]]
+	if !ok || model.SoftDeleteField == nil {
+		return b
+	}
+
+	fieldName := fmt.Sprintf("[!%s.%s]", model.ModelName, model.SoftDeleteField.Name)
+	if groups[2] != "" {
+		fieldName = fmt.Sprintf("%s.[%s.%s]", groups[2], model.ModelName, model.SoftDeleteField.Name)
+	}
+	// Where ANDs the condition to the existing one, and since And builds a new cond instead of
+	// changing it the caller's builder is left as it was: a shallow copy is enough
+	scoped := *b
+	return scoped.Where(builder.IsNull{fieldName})
+}
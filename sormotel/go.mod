@@ -0,0 +1,29 @@
+module github.com/n1xx1/sorm/sormotel
+
+go 1.22.0
+
+// to build against the local sorm, use a go.work in the parent directory:
+//
+//	go work init . ./sormotel ./sormprom
+//	go work edit -replace github.com/n1xx1/sorm@v0.0.0-20261018105200-e5b21131ad9d=./
+require (
+	github.com/n1xx1/sorm v0.0.0-20261018105200-e5b21131ad9d
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/sdk v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/denisenkom/go-mssqldb v0.0.0-20191001013358-cfbb681360f0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-sql-driver/mysql v1.4.1 // indirect
+	github.com/golang-sql/civil v0.0.0-20190719163853-cb61b32ac6fe // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/n1xx1/builder v0.3.5-0.20190612101549-e2e4763d15c4 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.35.0 // indirect
+	golang.org/x/crypto v0.0.0-20190325154230-a5d413f7728c // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
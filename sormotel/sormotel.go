@@ -0,0 +1,80 @@
+// Package sormotel creates an OpenTelemetry span for every operation performed by sorm.
+package sormotel
+
+import (
+	"context"
+	"github.com/n1xx1/sorm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/n1xx1/sorm/sormotel"
+
+// Hook is a sorm.Hook creating a client span for every statement, Begin, Commit and Rollback
+type Hook struct {
+	tracer trace.Tracer
+	system string
+}
+
+// Option configures the Hook
+type Option func(h *Hook)
+
+// WithTracerProvider sets the provider used to create the tracer, otel.GetTracerProvider() by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(h *Hook) {
+		h.tracer = provider.Tracer(instrumentationName)
+	}
+}
+
+// WithDBSystem sets the db.system attribute of the spans (e.g. "mysql")
+func WithDBSystem(system string) Option {
+	return func(h *Hook) {
+		h.system = system
+	}
+}
+
+// NewHook creates the hook, to be passed to sorm.Open with sorm.WithHook
+func NewHook(options ...Option) *Hook {
+	h := &Hook{}
+	for _, o := range options {
+		o(h)
+	}
+	if h.tracer == nil {
+		h.tracer = otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	return h
+}
+
+func (h *Hook) Before(ctx context.Context, e *sorm.HookEvent) context.Context {
+	name := "sorm." + string(e.Operation)
+	if e.Model != "" {
+		name += " " + e.Model
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.operation", string(e.Operation)),
+	}
+	if h.system != "" {
+		attrs = append(attrs, attribute.String("db.system", h.system))
+	}
+	if e.SQL != "" {
+		attrs = append(attrs, attribute.String("db.statement", e.SQL))
+	}
+	if e.Model != "" {
+		attrs = append(attrs, attribute.String("sorm.model", e.Model))
+	}
+
+	ctx, _ = h.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (h *Hook) After(ctx context.Context, e *sorm.HookEvent) {
+	span := trace.SpanFromContext(ctx)
+	if e.Err != nil {
+		span.RecordError(e.Err)
+		span.SetStatus(codes.Error, e.Err.Error())
+	}
+	span.End()
+}
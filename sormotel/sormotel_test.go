@@ -0,0 +1,32 @@
+package sormotel
+
+import (
+	"context"
+	"errors"
+	"github.com/n1xx1/sorm"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	hook := NewHook(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))), WithDBSystem("mysql"))
+
+	e := &sorm.HookEvent{Operation: sorm.OpFind, Model: "User", SQL: "SELECT 1"}
+	hook.After(hook.Before(context.Background(), e), e)
+	e = &sorm.HookEvent{Operation: sorm.OpCommit, Err: errors.New("failed")}
+	hook.After(hook.Before(context.Background(), e), e)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "sorm.find User" || len(spans[0].Attributes()) != 4 {
+		t.Errorf("unexpected span %q with %v", spans[0].Name(), spans[0].Attributes())
+	}
+	if spans[1].Name() != "sorm.commit" || spans[1].Status().Code != codes.Error {
+		t.Errorf("unexpected span %q with status %v", spans[1].Name(), spans[1].Status())
+	}
+}
@@ -0,0 +1,29 @@
+module github.com/n1xx1/sorm/sormprom
+
+go 1.22.0
+
+// to build against the local sorm, use a go.work in the parent directory:
+//
+//	go work init . ./sormotel ./sormprom
+//	go work edit -replace github.com/n1xx1/sorm@v0.0.0-20261018105200-e5b21131ad9d=./
+require (
+	github.com/n1xx1/sorm v0.0.0-20261018105200-e5b21131ad9d
+	github.com/prometheus/client_golang v1.20.5
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/denisenkom/go-mssqldb v0.0.0-20191001013358-cfbb681360f0 // indirect
+	github.com/go-sql-driver/mysql v1.4.1 // indirect
+	github.com/golang-sql/civil v0.0.0-20190719163853-cb61b32ac6fe // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/n1xx1/builder v0.3.5-0.20190612101549-e2e4763d15c4 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/crypto v0.0.0-20190325154230-a5d413f7728c // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
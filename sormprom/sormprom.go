@@ -0,0 +1,46 @@
+// Package sormprom records the duration of the operations performed by sorm in a Prometheus histogram.
+package sormprom
+
+import (
+	"context"
+	"github.com/n1xx1/sorm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Hook is a sorm.Hook observing the duration of every statement, Begin, Commit and Rollback
+// in the histogram sorm_operation_duration_seconds, labelled by operation, model and status
+type Hook struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewHook creates the hook and registers its histogram in registerer, buckets can be nil to
+// use prometheus.DefBuckets. The hook is meant to be passed to sorm.Open with sorm.WithHook.
+func NewHook(registerer prometheus.Registerer, buckets []float64) (*Hook, error) {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "sorm",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of the operations performed by sorm.",
+		Buckets:   buckets,
+	}, []string{"operation", "model", "status"})
+
+	err := registerer.Register(duration)
+	if err != nil {
+		return nil, err
+	}
+	return &Hook{duration: duration}, nil
+}
+
+func (h *Hook) Before(ctx context.Context, e *sorm.HookEvent) context.Context {
+	return ctx
+}
+
+func (h *Hook) After(ctx context.Context, e *sorm.HookEvent) {
+	status := "ok"
+	if e.Err != nil {
+		status = "error"
+	}
+	h.duration.WithLabelValues(string(e.Operation), e.Model, status).Observe(e.Duration.Seconds())
+}
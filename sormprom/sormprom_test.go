@@ -0,0 +1,35 @@
+package sormprom
+
+import (
+	"context"
+	"errors"
+	"github.com/n1xx1/sorm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+	"time"
+)
+
+func TestHook(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	hook, err := NewHook(registry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []*sorm.HookEvent{
+		{Operation: sorm.OpFind, Model: "User", Duration: time.Millisecond},
+		{Operation: sorm.OpFind, Model: "User", Duration: time.Millisecond},
+		{Operation: sorm.OpCommit, Err: errors.New("failed")},
+	}
+	for _, e := range events {
+		hook.After(hook.Before(context.Background(), e), e)
+	}
+
+	if n := testutil.CollectAndCount(registry, "sorm_operation_duration_seconds"); n != 2 {
+		t.Errorf("expected 2 series, got %d", n)
+	}
+	if _, err := NewHook(registry, nil); err == nil {
+		t.Errorf("expected an error registering the histogram twice")
+	}
+}
@@ -0,0 +1,82 @@
+//go:build cgo
+// +build cgo
+
+package sorm
+
+import (
+	"database/sql"
+	"github.com/n1xx1/builder"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestSqliteInProcess runs the statements against an in memory SQLite database, unlike the
+// other tests that only check the generated SQL
+func TestSqliteInProcess(t *testing.T) {
+	AddModel(&queryTestModel{})
+	AddModel(&softDeleteTestModel{})
+
+	sqldb, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection would have its own in memory database
+	sqldb.SetMaxOpenConns(1)
+	db := Open(sqldb, DriverSqlite, WithoutPing())
+	defer db.Close()
+
+	for _, ddl := range []string{
+		`CREATE TABLE query_test (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)`,
+		`CREATE TABLE soft_delete_test (id INTEGER PRIMARY KEY, deleted_at DATETIME)`,
+	} {
+		if _, err := sqldb.Exec(ddl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &queryTestModel{Name: "a"}
+	if err := Insert(db, m); err != nil {
+		t.Fatal(err)
+	}
+	many := []queryTestModel{{Name: "b"}, {Name: "c"}}
+	if err := InsertMany(db, many); err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != 1 || many[0].ID != 2 || many[1].ID != 3 {
+		t.Fatalf("unexpected ids %d, %d and %d", m.ID, many[0].ID, many[1].ID)
+	}
+	m.Name = "d"
+	if err := Update(db, m); err != nil {
+		t.Fatal(err)
+	}
+	// Upsert is not run, it needs RETURNING (SQLite 3.35) and the bundled SQLite is older
+	if err := UpdateAll(db, &queryTestModel{ID: 2, Name: "e"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeleteWhere(db, &queryTestModel{}, builder.Eq{"[!queryTestModel.ID]": 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []queryTestModel
+	if err := Find(db, builder.Select().OrderBy("id"), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Name != "d" || rows[1].Name != "e" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+
+	if err := Insert(db, &softDeleteTestModel{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(db, &softDeleteTestModel{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	n, err := Count(db, builder.Select().From("[softDeleteTestModel]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("expected the row to be soft deleted, got %d rows", n)
+	}
+}
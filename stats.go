@@ -0,0 +1,186 @@
+package sorm
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the statistics of a DB
+type Stats struct {
+	// Queries is the number of statements executed with Query
+	Queries int64
+	// Execs is the number of statements executed with Exec
+	Execs int64
+	// Errors is the number of statements that failed
+	Errors int64
+
+	// Duration is the total time spent executing statements
+	Duration      time.Duration
+	QueryDuration time.Duration
+	ExecDuration  time.Duration
+
+	// RowsScanned is the number of rows read by the QueryScanners
+	RowsScanned int64
+
+	Commits   int64
+	Rollbacks int64
+
+	// Operations are the statistics of the statements of every operation (like OpFind or
+	// OpInsert), only the operations with at least one statement are present (nil if none)
+	Operations map[Operation]OperationStats
+}
+
+// OperationStats are the statistics of the statements executed by an operation
+type OperationStats struct {
+	Count    int64
+	Errors   int64
+	Duration time.Duration
+}
+
+// statementOperations are the operations executing statements, which have their own statistics
+var statementOperations = [...]Operation{OpQuery, OpFind, OpScan, OpSelect, OpCount, OpInsert, OpUpdate, OpUpsert, OpDelete, OpExec}
+
+func operationIndex(op Operation) int {
+	for i, o := range statementOperations {
+		if o == op {
+			return i
+		}
+	}
+	return -1
+}
+
+type opStats struct {
+	count    int64
+	errors   int64
+	duration int64
+}
+
+// dbStats is updated atomically, so it can be shared by many goroutines.
+// If parent is not nil everything is also added to it.
+type dbStats struct {
+	queries       int64
+	execs         int64
+	errors        int64
+	queryDuration int64
+	execDuration  int64
+	rowsScanned   int64
+	commits       int64
+	rollbacks     int64
+	ops           [len(statementOperations)]opStats
+
+	parent *dbStats
+}
+
+func (s *dbStats) addQuery(op Operation, duration time.Duration, err error) {
+	for ; s != nil; s = s.parent {
+		atomic.AddInt64(&s.queries, 1)
+		atomic.AddInt64(&s.queryDuration, int64(duration))
+		if err != nil {
+			atomic.AddInt64(&s.errors, 1)
+		}
+		s.addOperation(op, duration, err)
+	}
+}
+
+func (s *dbStats) addExec(op Operation, duration time.Duration, err error) {
+	for ; s != nil; s = s.parent {
+		atomic.AddInt64(&s.execs, 1)
+		atomic.AddInt64(&s.execDuration, int64(duration))
+		if err != nil {
+			atomic.AddInt64(&s.errors, 1)
+		}
+		s.addOperation(op, duration, err)
+	}
+}
+
+func (s *dbStats) addOperation(op Operation, duration time.Duration, err error) {
+	i := operationIndex(op)
+	if i < 0 {
+		return
+	}
+	atomic.AddInt64(&s.ops[i].count, 1)
+	atomic.AddInt64(&s.ops[i].duration, int64(duration))
+	if err != nil {
+		atomic.AddInt64(&s.ops[i].errors, 1)
+	}
+}
+
+func (s *dbStats) addRow() {
+	for ; s != nil; s = s.parent {
+		atomic.AddInt64(&s.rowsScanned, 1)
+	}
+}
+
+func (s *dbStats) addCommit() {
+	for ; s != nil; s = s.parent {
+		atomic.AddInt64(&s.commits, 1)
+	}
+}
+
+func (s *dbStats) addRollback() {
+	for ; s != nil; s = s.parent {
+		atomic.AddInt64(&s.rollbacks, 1)
+	}
+}
+
+func (s *dbStats) snapshot() Stats {
+	ret := Stats{
+		Queries:       atomic.LoadInt64(&s.queries),
+		Execs:         atomic.LoadInt64(&s.execs),
+		Errors:        atomic.LoadInt64(&s.errors),
+		QueryDuration: time.Duration(atomic.LoadInt64(&s.queryDuration)),
+		ExecDuration:  time.Duration(atomic.LoadInt64(&s.execDuration)),
+		RowsScanned:   atomic.LoadInt64(&s.rowsScanned),
+		Commits:       atomic.LoadInt64(&s.commits),
+		Rollbacks:     atomic.LoadInt64(&s.rollbacks),
+	}
+	ret.Duration = ret.QueryDuration + ret.ExecDuration
+	for i, op := range statementOperations {
+		o := &s.ops[i]
+		if count := atomic.LoadInt64(&o.count); count != 0 {
+			if ret.Operations == nil {
+				ret.Operations = map[Operation]OperationStats{}
+			}
+			ret.Operations[op] = OperationStats{
+				Count:    count,
+				Errors:   atomic.LoadInt64(&o.errors),
+				Duration: time.Duration(atomic.LoadInt64(&o.duration)),
+			}
+		}
+	}
+	return ret
+}
+
+func (s *dbStats) reset() {
+	atomic.StoreInt64(&s.queries, 0)
+	atomic.StoreInt64(&s.execs, 0)
+	atomic.StoreInt64(&s.errors, 0)
+	atomic.StoreInt64(&s.queryDuration, 0)
+	atomic.StoreInt64(&s.execDuration, 0)
+	atomic.StoreInt64(&s.rowsScanned, 0)
+	atomic.StoreInt64(&s.commits, 0)
+	atomic.StoreInt64(&s.rollbacks, 0)
+	for i := range s.ops {
+		atomic.StoreInt64(&s.ops[i].count, 0)
+		atomic.StoreInt64(&s.ops[i].errors, 0)
+		atomic.StoreInt64(&s.ops[i].duration, 0)
+	}
+}
+
+// Stats returns a snapshot of the statistics, shared with the clones returned by Debug
+func (q *DB) Stats() Stats {
+	return q.stats.snapshot()
+}
+
+// ResetStats sets all the statistics to zero
+func (q *DB) ResetStats() {
+	q.stats.reset()
+}
+
+// WithStats returns a clone of the DB with its own statistics, starting from zero, which are
+// also added to the ones of q. It can be used to collect the statistics of a single request.
+func (q *DB) WithStats() *DB {
+	clone := *q
+	clone.stats = &dbStats{parent: q.stats}
+	return &clone
+}
@@ -0,0 +1,67 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("FROM `query_test`", []string{"q0", "q1"},
+		[]driver.Value{int64(1), "a"},
+		[]driver.Value{int64(2), "b"})
+
+	request := db.WithStats()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var dest []queryTestModel
+			if err := Find(request, builder.MySQL(), &dest); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	err := db.Begin(func(q *TX) error {
+		return Exec(q, builder.MySQL().From("[queryTestModel]").Delete(builder.Eq{"[!queryTestModel.ID]": 1}))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Begin(func(q *TX) error {
+		return errors.New("rollback")
+	})
+
+	stats := db.Stats()
+	if stats.Queries != 8 || stats.Execs != 1 || stats.RowsScanned != 16 || stats.Commits != 1 || stats.Rollbacks != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if stats.Duration != stats.QueryDuration+stats.ExecDuration {
+		t.Fatalf("unexpected durations %+v", stats)
+	}
+
+	requestStats := request.Stats()
+	if requestStats.Queries != 8 || requestStats.Execs != 0 || requestStats.RowsScanned != 16 || requestStats.Commits != 0 {
+		t.Fatalf("unexpected request stats %+v", requestStats)
+	}
+	if find := stats.Operations[OpFind]; find.Count != 8 || find.Duration > stats.QueryDuration || len(stats.Operations) != 2 {
+		t.Fatalf("unexpected operation stats %+v", stats.Operations)
+	}
+	if exec := stats.Operations[OpExec]; exec.Count != 1 || exec.Errors != 0 {
+		t.Fatalf("unexpected exec stats %+v", exec)
+	}
+
+	db.ResetStats()
+	if !reflect.DeepEqual(db.Stats(), Stats{}) {
+		t.Fatalf("expected empty stats, got %+v", db.Stats())
+	}
+}
@@ -0,0 +1,244 @@
+package sorm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+var errUnpreparable = errors.New("the statement can't be prepared")
+
+// WithStatementCache enables a cache of the last size statements executed by the DB: both the
+// result of FormatQuery and ConvertQuery (keyed by the builder output) and the prepared
+// statements, which are executed in place of the unprepared ones. Inside a transaction the
+// prepared statements are bound to it with Tx.StmtContext.
+func WithStatementCache(size int) OpenOption {
+	return func(o *openOptions) {
+		o.stmtCacheSize = size
+	}
+}
+
+// lruCache is a fixed size cache discarding the least recently used entries, calling onEvict on them
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	items   map[string]*list.Element
+	onEvict func(value interface{})
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+func newLRUCache(size int, onEvict func(value interface{})) *lruCache {
+	return &lruCache{
+		size:    size,
+		ll:      list.New(),
+		items:   map[string]*list.Element{},
+		onEvict: onEvict,
+	}
+}
+
+// getLocked returns the value of key, marking it as the most recently used
+func (c *lruCache) getLocked(key string) (interface{}, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+// addLocked adds the value to the cache, evicting the least recently used entry if needed
+func (c *lruCache) addLocked(key string, value interface{}) {
+	c.items[key] = c.ll.PushFront(&lruEntry{key, value})
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		entry := c.ll.Remove(el).(*lruEntry)
+		delete(c.items, entry.key)
+		if c.onEvict != nil {
+			c.onEvict(entry.value)
+		}
+	}
+}
+
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key)
+}
+
+func (c *lruCache) add(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	c.addLocked(key, value)
+}
+
+// purge evicts every entry
+func (c *lruCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		if c.onEvict != nil {
+			c.onEvict(el.Value.(*lruEntry).value)
+		}
+	}
+	c.ll.Init()
+	c.items = map[string]*list.Element{}
+}
+
+type stmtCache struct {
+	queries *lruCache
+	stmts   *lruCache
+	// unpreparable holds the statements the database refused to prepare, which are
+	// always executed unprepared instead of retrying the prepare every time
+	unpreparable *lruCache
+}
+
+// formattedQuery is the cached result of FormatQuery and ConvertQuery, argIndexes[i] is the
+// index of the original arg that ConvertQuery put in position i
+type formattedQuery struct {
+	sql        string
+	argIndexes []int
+}
+
+// cachedStmt is a prepared statement that is closed when it's evicted and no longer in use
+type cachedStmt struct {
+	stmt    *sql.Stmt
+	users   int
+	evicted bool
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		queries: newLRUCache(size, nil),
+		stmts: newLRUCache(size, func(value interface{}) {
+			s := value.(*cachedStmt)
+			s.evicted = true
+			if s.users == 0 {
+				_ = s.stmt.Close()
+			}
+		}),
+		unpreparable: newLRUCache(size, nil),
+	}
+}
+
+// format is FormatQuery followed by ConvertQuery, the result is cached assuming that
+// ConvertQuery only reorders (or repeats) the args
+func (c *stmtCache) format(driver Driver, sql1 string, args []interface{}) (string, []interface{}) {
+	var f *formattedQuery
+	if v, ok := c.queries.get(sql1); ok {
+		f = v.(*formattedQuery)
+	} else {
+		// convert the indexes instead of the args to know where every arg ends up
+		indexes := make([]interface{}, len(args))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		query, converted := ConvertQuery(driver, FormatQuery(driver, sql1), indexes)
+
+		f = &formattedQuery{sql: query, argIndexes: make([]int, len(converted))}
+		for i, index := range converted {
+			f.argIndexes[i] = index.(int)
+		}
+		c.queries.add(sql1, f)
+	}
+
+	realArgs := make([]interface{}, len(f.argIndexes))
+	for i, index := range f.argIndexes {
+		realArgs[i] = args[index]
+	}
+	return f.sql, realArgs
+}
+
+// stmt returns the prepared statement for query, preparing it if it's not cached.
+// release must be called once the statement is not used anymore.
+func (c *stmtCache) stmt(ctx context.Context, db *sql.DB, query string) (stmt *sql.Stmt, release func(), err error) {
+	c.stmts.mu.Lock()
+	if v, ok := c.stmts.getLocked(query); ok {
+		s := v.(*cachedStmt)
+		s.users++
+		c.stmts.mu.Unlock()
+		return s.stmt, c.releaser(s), nil
+	}
+	c.stmts.mu.Unlock()
+
+	if _, ok := c.unpreparable.get(query); ok {
+		return nil, nil, errUnpreparable
+	}
+	stmt, err = db.PrepareContext(ctx, query)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.unpreparable.add(query, struct{}{})
+		}
+		return nil, nil, err
+	}
+
+	c.stmts.mu.Lock()
+	defer c.stmts.mu.Unlock()
+
+	s := &cachedStmt{stmt: stmt, users: 1}
+	if v, ok := c.stmts.getLocked(query); ok {
+		// prepared concurrently by someone else
+		_ = stmt.Close()
+		s = v.(*cachedStmt)
+		s.users++
+	} else {
+		c.stmts.addLocked(query, s)
+	}
+	return s.stmt, c.releaser(s), nil
+}
+
+// prepared is like stmt, but returns a nil statement when the cache is disabled (c is nil) or the
+// statement can't be prepared, in which case it should be executed unprepared
+func (c *stmtCache) prepared(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, func()) {
+	if c == nil {
+		return nil, nil
+	}
+	stmt, release, err := c.stmt(ctx, db, query)
+	if err != nil {
+		return nil, nil
+	}
+	return stmt, release
+}
+
+// cached is like prepared, but it never prepares the statement: it's used inside transactions,
+// where preparing on the pool would need a second connection while the transaction holds one
+func (c *stmtCache) cached(query string) (*sql.Stmt, func()) {
+	if c == nil {
+		return nil, nil
+	}
+	c.stmts.mu.Lock()
+	defer c.stmts.mu.Unlock()
+	v, ok := c.stmts.getLocked(query)
+	if !ok {
+		return nil, nil
+	}
+	s := v.(*cachedStmt)
+	s.users++
+	return s.stmt, c.releaser(s)
+}
+
+func (c *stmtCache) releaser(s *cachedStmt) func() {
+	return func() {
+		c.stmts.mu.Lock()
+		defer c.stmts.mu.Unlock()
+		s.users--
+		if s.evicted && s.users == 0 {
+			_ = s.stmt.Close()
+		}
+	}
+}
+
+func (c *stmtCache) close() {
+	c.queries.purge()
+	c.stmts.purge()
+	c.unpreparable.purge()
+}
@@ -0,0 +1,112 @@
+package sorm
+
+import (
+	"context"
+	"errors"
+	"github.com/n1xx1/builder"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatementCacheFormat(t *testing.T) {
+	c := newStmtCache(10)
+
+	for _, args := range [][]interface{}{{"a", 1}, {"b", 2}} {
+		sql1, converted := c.format(DriverMysql, "SELECT * FROM t WHERE b = @p2 AND a = @p1 AND c = @p2", args)
+		if sql1 != "SELECT * FROM t WHERE b = ? AND a = ? AND c = ?" {
+			t.Errorf("unexpected query %q", sql1)
+		}
+		expected := []interface{}{args[1], args[0], args[1]}
+		if !reflect.DeepEqual(converted, expected) {
+			t.Errorf("expected %v, got %v", expected, converted)
+		}
+	}
+}
+
+func TestStatementCache(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMysql, WithStatementCache(1))
+
+	for i := 0; i < 3; i++ {
+		if err := Insert(db, &queryTestModel{Name: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := db.Begin(func(q *TX) error {
+		return Insert(q, &queryTestModel{Name: "b"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.prepared != 1 || fake.closed != 0 {
+		t.Fatalf("expected a single prepared statement, got %d prepared and %d closed", fake.prepared, fake.closed)
+	}
+
+	// a different statement evicts the previous one
+	if _, err := DeleteWhere(db, &queryTestModel{}, builder.Eq{"name": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if fake.prepared != 2 || fake.closed != 1 {
+		t.Fatalf("expected the first statement to be closed, got %d prepared and %d closed", fake.prepared, fake.closed)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fake.closed != 2 {
+		t.Fatalf("expected every statement to be closed, got %d closed", fake.closed)
+	}
+}
+
+func TestStatementCacheTransactionSingleConn(t *testing.T) {
+	AddModel(&queryTestModel{})
+	sqldb, fake := newFakeSQLDB()
+	sqldb.SetMaxOpenConns(1)
+	db := Open(sqldb, DriverMysql, WithoutPing(), WithStatementCache(10))
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := db.BeginContext(ctx, func(q *TX) error {
+		return InsertContext(ctx, q, &queryTestModel{Name: "a"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.prepared != 0 {
+		t.Fatalf("expected no statement prepared inside the transaction, got %d", fake.prepared)
+	}
+
+	// once cached outside, the statement is used inside the transaction too
+	if err := Insert(db, &queryTestModel{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	err = db.BeginContext(ctx, func(q *TX) error {
+		return InsertContext(ctx, q, &queryTestModel{Name: "c"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.prepared != 1 {
+		t.Fatalf("expected a single prepared statement, got %d", fake.prepared)
+	}
+}
+
+func TestStatementCacheUnpreparable(t *testing.T) {
+	AddModel(&queryTestModel{})
+	db, fake := newFakeDB(DriverMysql, WithStatementCache(10))
+	fake.setPrepareError("INSERT", errors.New("not supported"))
+
+	for i := 0; i < 3; i++ {
+		if err := Insert(db, &queryTestModel{Name: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(fake.log()); n != 3 {
+		t.Fatalf("expected 3 statements, got %d", n)
+	}
+	if _, ok := db.stmtCache.unpreparable.get(fake.log()[0]); !ok {
+		t.Fatal("expected the failed prepare to be remembered")
+	}
+}
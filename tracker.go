@@ -0,0 +1,116 @@
+package sorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Tracker can be embedded in a model to use Save. It holds a snapshot of the values of the
+// fields, taken every time the model is scanned by a query, inserted or saved.
+type Tracker struct {
+	snapshot []interface{}
+}
+
+func (t *Tracker) tracker() *Tracker {
+	return t
+}
+
+type tracked interface {
+	tracker() *Tracker
+}
+
+// snapshotValue returns a copy of the value of a field, which doesn't change with the field
+func snapshotValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return append([]byte(nil), v.Bytes()...)
+	}
+	return v.Interface()
+}
+
+// Snapshot records the current values of the fields of i, a pointer to a model embedding
+// Tracker, so that Save only updates the fields changed after it. It does nothing for other models.
+func Snapshot(i interface{}) {
+	t, ok := i.(tracked)
+	if !ok {
+		return
+	}
+	v := reflect.ValueOf(i).Elem()
+	model := modelCache[v.Type()]
+	if model == nil {
+		panic("model not found")
+	}
+
+	snapshot := make([]interface{}, len(model.Fields))
+	for j, f := range model.Fields {
+		snapshot[j] = snapshotValue(v.FieldByIndex(f.StructFieldPath))
+	}
+	t.tracker().snapshot = snapshot
+}
+
+// changedFields returns the fields whose value is different from the snapshot, all of them
+// if there is no snapshot
+func changedFields(model *ModelInfo, v reflect.Value, snapshot []interface{}) []*FieldInfo {
+	if snapshot == nil {
+		return model.Fields
+	}
+	var changed []*FieldInfo
+	for j, f := range model.Fields {
+		if !reflect.DeepEqual(snapshot[j], snapshotValue(v.FieldByIndex(f.StructFieldPath))) {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+func doSave(ctx context.Context, calldepth int, q DBTX, i interface{}) error {
+	t, ok := i.(tracked)
+	if !ok {
+		return fmt.Errorf("%T doesn't embed sorm.Tracker", i)
+	}
+	v := reflect.ValueOf(i).Elem()
+	model := modelCache[v.Type()]
+	if model == nil {
+		panic("model not found")
+	}
+
+	snapshot := t.tracker().snapshot
+	changed := changedFields(model, v, snapshot)
+	if len(changed) == 0 {
+		return nil
+	}
+	for _, f := range changed {
+		if f.IsPrimary && snapshot != nil {
+			// the WHERE would use the new value, so the change can't be saved
+			return ErrPrimaryKeyChanged
+		}
+	}
+	err := doUpdateFields(ctx, calldepth+1, q, i, func(model *ModelInfo) ([]*FieldInfo, error) {
+		return changed, nil
+	})
+	if err != nil {
+		return err
+	}
+	Snapshot(i)
+	return nil
+}
+
+// Save updates the fields of the model changed since it was scanned by a query, inserted,
+// saved or passed to Snapshot, or all of them if none of these happened. Nothing is executed
+// if there are no changes. The model must be a pointer to a struct embedding Tracker and,
+// like UpdateFields, it uses the primary fields for the WHERE, so they are never updated:
+// if one of them changed ErrPrimaryKeyChanged is returned.
+func Save(q DBTX, i interface{}) error {
+	return doSave(context.Background(), 1, q, i)
+}
+
+// SaveContext is like Save but the query is bound to ctx
+func SaveContext(ctx context.Context, q DBTX, i interface{}) error {
+	return doSave(ctx, 1, q, i)
+}
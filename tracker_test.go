@@ -0,0 +1,71 @@
+package sorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type trackerTestModel struct {
+	Tracker
+	ID    int     `db:"id,primary,autoincrement"`
+	Name  string  `db:"name"`
+	Notes *string `db:"notes"`
+}
+
+func (*trackerTestModel) TableName() string {
+	return "tracker_test"
+}
+
+func TestSave(t *testing.T) {
+	AddModel(&trackerTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+	fake.setRows("tracker_test", []string{"q0", "q1", "q2"}, []driver.Value{int64(1), "a", "b"})
+
+	m := &trackerTestModel{ID: 1}
+	if err := Select(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(db, m); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Name = ""
+	if err := Save(db, m); err != nil {
+		t.Fatal(err)
+	}
+	*m.Notes = "c"
+	if err := Save(db, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(db, m); err != nil {
+		t.Fatal(err)
+	}
+
+	log := fake.log()[1:]
+	expected := []string{
+		"UPDATE `tracker_test` SET `name`=? WHERE `tracker_test`.`id`=?",
+		"UPDATE `tracker_test` SET `notes`=? WHERE `tracker_test`.`id`=?",
+	}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+}
+
+func TestSavePrimaryKeyChanged(t *testing.T) {
+	AddModel(&trackerTestModel{})
+	db, fake := newFakeDB(DriverMysql)
+
+	m := &trackerTestModel{ID: 1, Name: "a"}
+	Snapshot(m)
+	m.ID = 2
+	for i := 0; i < 2; i++ {
+		// the snapshot is kept, so it fails every time
+		if err := Save(db, m); err != ErrPrimaryKeyChanged {
+			t.Fatalf("expected ErrPrimaryKeyChanged, got %v", err)
+		}
+	}
+	if log := fake.log(); len(log) != 0 {
+		t.Errorf("expected no statement, got %q", log)
+	}
+}
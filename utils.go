@@ -1,6 +1,7 @@
 package sorm
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -23,19 +24,27 @@ func CloneBuilder(b *builder.Builder) *builder.Builder {
 }
 
 func PagedQuery(q DBTX, current int, rpp int, counter *builder.Builder, selector *builder.Builder, dest interface{}) (int, error) {
+	return doPagedQuery(context.Background(), 1, q, current, rpp, counter, selector, dest)
+}
+
+func PagedQueryContext(ctx context.Context, q DBTX, current int, rpp int, counter *builder.Builder, selector *builder.Builder, dest interface{}) (int, error) {
+	return doPagedQuery(ctx, 1, q, current, rpp, counter, selector, dest)
+}
+
+func doPagedQuery(ctx context.Context, calldepth int, q DBTX, current int, rpp int, counter *builder.Builder, selector *builder.Builder, dest interface{}) (int, error) {
 	// TODO: maybe perPage sanitization should be moved somewhere else
 	if rpp != 10 && rpp != 20 && rpp != 50 {
 		rpp = 10
 	}
 
-	total, err := doCountTx(1, q, counter)
+	total, err := doCountTx(ctx, calldepth+1, q, counter)
 	if err != nil {
 		return 0, err
 	}
 
-	selector = selector.Limit(rpp, current*rpp)
+	selector = q.Driver().Dialect().Limit(selector, rpp, current*rpp)
 
-	err = doFindTx(1, q, selector, dest)
+	err = doFindTx(ctx, calldepth+1, q, selector, dest)
 	if err != nil {
 		return 0, err
 	}
@@ -44,24 +53,62 @@ func PagedQuery(q DBTX, current int, rpp int, counter *builder.Builder, selector
 
 var dbl = log.New(os.Stderr, "\r\n", 0)
 
-func timedQuery(q DBTX, sql1 string, args []interface{}, calldepth int) (*sql.Rows, error) {
+func timedQuery(ctx context.Context, q DBTX, sql1 string, args []interface{}, calldepth int) (*sql.Rows, error) {
+	ctx, hooks := q.base().beforeStatement(ctx, OpQuery, sql1)
 	start := time.Now()
-	rows, err := q.Query(sql1, args...)
-	if q.debugMode() {
-		dbl.Println(logFormatter(sql1, args, fileLocation(calldepth), time.Since(start))...)
-	}
+	rows, err := q.QueryContext(ctx, sql1, args...)
+	reportStatement(q, sql1, args, calldepth+1, time.Since(start), nil, err)
+	q.base().afterHooks(hooks, err)
 	return rows, err
 }
 
-func timedExec(q DBTX, sql1 string, args []interface{}, calldepth int) (sql.Result, error) {
+func timedExec(ctx context.Context, q DBTX, sql1 string, args []interface{}, calldepth int) (sql.Result, error) {
+	ctx, hooks := q.base().beforeStatement(ctx, OpExec, sql1)
 	start := time.Now()
-	res, err := q.Exec(sql1, args...)
-	if q.debugMode() {
-		dbl.Println(logFormatter(sql1, args, fileLocation(calldepth), time.Since(start))...)
-	}
+	res, err := q.ExecContext(ctx, sql1, args...)
+	reportStatement(q, sql1, args, calldepth+1, time.Since(start), res, err)
+	q.base().afterHooks(hooks, err)
 	return res, err
 }
 
+// reportStatement sends the executed statement to the query logger when in debug mode
+// and to the slow query handler when it took longer than the threshold
+func reportStatement(q DBTX, sql1 string, args []interface{}, calldepth int, duration time.Duration, res sql.Result, err error) {
+	db := q.base()
+	slow := db.slowThreshold > 0 && duration >= db.slowThreshold
+	if !q.debugMode() && !slow {
+		return
+	}
+
+	affected := int64(-1)
+	if res != nil && err == nil {
+		if n, err := res.RowsAffected(); err == nil {
+			affected = n
+		}
+	}
+	e := &QueryEvent{
+		Time:            time.Now(),
+		SQL:             sql1,
+		InterpolatedSQL: interpolateQuery(sql1, args),
+		Args:            args,
+		Caller:          fileLocation(calldepth),
+		Duration:        duration,
+		RowsAffected:    affected,
+		Err:             err,
+		Slow:            slow,
+	}
+
+	if slow && db.slowHandler != nil {
+		db.slowHandler(e)
+		if q.debugMode() {
+			db.logQuery(e)
+		}
+		return
+	}
+	// without a handler, slow queries are logged even outside of debug mode
+	db.logQuery(e)
+}
+
 func fileLocation(calldepth int) string {
 	_, file, line, ok := runtime.Caller(calldepth + 3)
 	if !ok {
@@ -80,18 +127,30 @@ func isPrintable(s string) bool {
 	return true
 }
 
-var sqlRegexp = regexp.MustCompile(`(?:\?|@p(\d+))`)
+var sqlRegexp = regexp.MustCompile(`(?:\?|@p(\d+)|\$(\d+))`)
 
-func logFormatter(sql string, args []interface{}, file string, duration time.Duration) []interface{} {
-	currentTime := "\n\033[33m[" + time.Now().Format("2006-01-02 15:04:05") + "]\033[0m"
-	source := fmt.Sprintf("\033[35m(%v)\033[0m", file)
+func logFormatter(e *QueryEvent) []interface{} {
+	currentTime := "\n\033[33m[" + e.Time.Format("2006-01-02 15:04:05") + "]\033[0m"
+	source := fmt.Sprintf("\033[35m(%v)\033[0m", e.Caller)
 
 	messages := []interface{}{source, currentTime}
 
-	if duration > 0 {
-		messages = append(messages, fmt.Sprintf(" \033[36;1m[%.2fms]\033[0m ", float64(duration.Nanoseconds()/1e4)/100.0))
+	if e.Duration > 0 {
+		messages = append(messages, fmt.Sprintf(" \033[36;1m[%.2fms]\033[0m ", float64(e.Duration.Nanoseconds()/1e4)/100.0))
+	}
+	if e.Slow {
+		messages = append(messages, "\033[31;1m[SLOW]\033[0m ")
+	}
+
+	messages = append(messages, e.InterpolatedSQL)
+	if e.Err != nil {
+		messages = append(messages, fmt.Sprintf("\n\033[31m%v\033[0m", e.Err))
 	}
+	return messages
+}
 
+// interpolateQuery replaces the placeholders in sql with the formatted args
+func interpolateQuery(sql string, args []interface{}) string {
 	formattedValues := make([]string, len(args))
 	for i, value := range args {
 		indirectValue := reflect.Indirect(reflect.ValueOf(value))
@@ -122,18 +181,19 @@ func logFormatter(sql string, args []interface{}, file string, duration time.Dur
 	}
 
 	index := 0
-	formattedSql := ReplaceAllStringSubmatchFunc(sqlRegexp, sql, func(groups []string) string {
-		if groups[1] != "" {
-			pos, _ := strconv.ParseInt(groups[1], 10, 32)
+	return ReplaceAllStringSubmatchFunc(sqlRegexp, sql, func(groups []string) string {
+		if groups[1] != "" || groups[2] != "" {
+			pos, _ := strconv.ParseInt(groups[1]+groups[2], 10, 32)
 			index = int(pos) - 1
 		}
+		if index < 0 || index >= len(formattedValues) {
+			// more placeholders than args, leave it as is
+			return groups[0]
+		}
 		ret := formattedValues[index]
 		index++
 		return ret
 	})
-
-	messages = append(messages, formattedSql)
-	return messages
 }
 
 func ReplaceAllStringSubmatchFunc(re *regexp.Regexp, str string, repl func(groups []string) string) string {